@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto"
@@ -32,11 +33,47 @@ type BaseClient interface {
 	clientConfig() clientconfig.ClientConfig
 	Encrypt(ctx context.Context, source []byte, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) ([]byte, *serialization.MessageHeader, error)
 	EncryptWithParams(ctx context.Context, source []byte, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, algorithm *suite.AlgorithmSuite, frameLength int) ([]byte, *serialization.MessageHeader, error)
-	Decrypt(ctx context.Context, ciphertext []byte, materialsManager model.CryptoMaterialsManager) ([]byte, *serialization.MessageHeader, error)
+	Decrypt(ctx context.Context, ciphertext []byte, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) ([]byte, *serialization.MessageHeader, error)
 }
 
 var _ BaseClient = (*Client)(nil)
 
+// EncryptWriterClient encrypts a stream of plaintext written to an [io.Writer].
+type EncryptWriterClient interface {
+	NewEncryptWriter(ctx context.Context, dst io.Writer, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) (io.WriteCloser, *serialization.MessageHeader, error)
+}
+
+var _ EncryptWriterClient = (*Client)(nil)
+
+// DecryptReaderClient decrypts a message read from an [io.Reader] as a stream of plaintext.
+type DecryptReaderClient interface {
+	NewDecryptReader(ctx context.Context, src io.Reader, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (io.ReadCloser, *serialization.MessageHeader, error)
+}
+
+var _ DecryptReaderClient = (*Client)(nil)
+
+// MessageIteratorClient decrypts concatenated messages read from an [io.Reader] one by one.
+type MessageIteratorClient interface {
+	NewMessageIterator(ctx context.Context, src io.Reader, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (*crypto.MessageIterator, error)
+}
+
+var _ MessageIteratorClient = (*Client)(nil)
+
+// RangeDecrypterClient decrypts byte ranges of a framed message read from an [io.ReaderAt].
+type RangeDecrypterClient interface {
+	NewRangeDecrypter(ctx context.Context, src io.ReaderAt, size int64, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (*crypto.RangeDecrypter, *serialization.MessageHeader, error)
+}
+
+var _ RangeDecrypterClient = (*Client)(nil)
+
+// AppendClient encrypts and decrypts into caller provided buffers.
+type AppendClient interface {
+	EncryptTo(ctx context.Context, dst, source []byte, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) ([]byte, *serialization.MessageHeader, error)
+	DecryptTo(ctx context.Context, dst, ciphertext []byte, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) ([]byte, *serialization.MessageHeader, error)
+}
+
+var _ AppendClient = (*Client)(nil)
+
 type Client struct {
 	config clientconfig.ClientConfig
 }
@@ -66,6 +103,8 @@ func (c *Client) EncryptWithParams(ctx context.Context, source []byte, ec suite.
 
 // Encrypt encrypts the given source data using the provided materials manager and encryption context.
 // By default, it uses the algorithm [suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384] and a frame length of 4096.
+// With [suite.CommitmentPolicyForbidEncryptAllowDecrypt] commitment policy, the default algorithm is
+// [suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384].
 //
 // This behavior can be modified by passing in optional functional arguments using EncryptOptionFunc.
 // It returns the ciphertext data along with the message header.
@@ -98,41 +137,419 @@ func (c *Client) EncryptWithParams(ctx context.Context, source []byte, ec suite.
 //  1. The Encrypt function allows customization of the encryption process through its optional parameters.
 //  2. The WithAlgorithm and WithFrameLength functions can be used to specify an encryption algorithm and frame length,
 //     respectively. If these functions are not used, default values are applied.
+//  3. The WithContentType function can be used to produce non-framed messages.
+//  4. The WithConcurrency function can be used to seal frames in parallel.
+//  5. Encryption stops between frames once ctx is canceled or its deadline is exceeded,
+//     the returned error wraps ctx.Err().
 func (c *Client) Encrypt(ctx context.Context, source []byte, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) ([]byte, *serialization.MessageHeader, error) {
+	params, err := c.encryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, header, err := crypto.EncryptWithParams(ctx, c.clientConfig(), source, ec, materialsManager, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, header, nil
+}
+
+// EncryptTo encrypts source like Encrypt and appends the encrypted message to dst.
+// Defaults and options are the same as for Encrypt.
+//
+// Frames are sealed from source directly into dst. If dst has enough spare capacity,
+// as computed by CiphertextLength, the message is written in place without any
+// allocation for the payload, otherwise dst is grown like with append.
+// dst may share its backing array with source only within its length: if the spare
+// capacity of dst overlaps source, the message is written to a newly allocated slice.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - dst []byte: The slice the encrypted message is appended to, it may be nil.
+//   - source []byte: The data to encrypt.
+//   - ec [suite.EncryptionContext]: The encryption context.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns EncryptOptionFunc: A variadic set of optional functions for configuring encryption options.
+//
+// Returns:
+//   - []byte: The extended dst slice, holding the encrypted message after the original dst content.
+//   - [serialization.MessageHeader]: The header of the encrypted message.
+//   - error: An error if encryption fails.
+//
+// Example usage:
+//
+//	size, err := client.CiphertextLength(int64(len(plaintext)), encryptionContext, edks)
+//	if err != nil {
+//	    // handle error
+//	}
+//	ciphertext, header, err := client.EncryptTo(context.TODO(), make([]byte, 0, size), plaintext,
+//	    encryptionContext, materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+func (c *Client) EncryptTo(ctx context.Context, dst, source []byte, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) ([]byte, *serialization.MessageHeader, error) {
+	params, err := c.encryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.EncryptTo(ctx, c.clientConfig(), dst, source, ec, materialsManager, params)
+}
+
+// NewEncryptWriter returns an [io.WriteCloser] that encrypts everything written to it
+// into dst, without holding the whole plaintext or ciphertext in memory.
+// Defaults and options are the same as for Encrypt.
+//
+// Encryption materials are obtained and the message header is written to dst
+// before NewEncryptWriter returns. Each frame is written to dst as soon as
+// frame length bytes of plaintext are written. Close writes the final frame
+// and the footer, it must be called to complete the message. Close does not close dst.
+//
+// Non-framed content, set by WithContentType, is buffered in memory until Close.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - dst io.Writer: The destination of the encrypted message.
+//   - ec [suite.EncryptionContext]: The encryption context.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns EncryptOptionFunc: A variadic set of optional functions for configuring encryption options.
+//
+// Returns:
+//   - io.WriteCloser: The plaintext writer.
+//   - [serialization.MessageHeader]: The header of the encrypted message.
+//   - error: An error if obtaining materials or writing the header fails.
+//
+// Example usage:
+//
+//	w, header, err := client.NewEncryptWriter(context.TODO(), file, encryptionContext, materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+//	if _, err := io.Copy(w, source); err != nil {
+//	    // handle error
+//	}
+//	if err := w.Close(); err != nil {
+//	    // handle error
+//	}
+func (c *Client) NewEncryptWriter(ctx context.Context, dst io.Writer, ec suite.EncryptionContext, materialsManager model.CryptoMaterialsManager, optFns ...EncryptOptionFunc) (io.WriteCloser, *serialization.MessageHeader, error) {
+	params, err := c.encryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.NewEncryptWriter(ctx, c.clientConfig(), dst, ec, materialsManager, params)
+}
+
+// encryptParams applies encrypt options over client defaults.
+func (c *Client) encryptParams(optFns ...EncryptOptionFunc) (crypto.EncryptParams, error) {
 	opts := EncryptOptions{
-		Algorithm:   suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384,
+		Algorithm:   defaultAlgorithm(c.config.CommitmentPolicy()),
 		FrameLength: DefaultFrameLength,
+		ContentType: suite.FramedContent,
+		Concurrency: 1,
 	}
 	for _, optFn := range optFns {
 		if err := optFn(&opts); err != nil {
-			return nil, nil, fmt.Errorf("invalid encrypt option: %w", errors.Join(crypto.ErrEncryption, err))
+			return crypto.EncryptParams{}, fmt.Errorf("invalid encrypt option: %w", errors.Join(crypto.ErrEncryption, err))
 		}
 	}
-	ciphertext, header, err := crypto.Encrypt(ctx, c.clientConfig(), source, ec, materialsManager, opts.Algorithm, opts.FrameLength)
+	return crypto.EncryptParams{
+		Algorithm:   opts.Algorithm,
+		FrameLength: opts.FrameLength,
+		ContentType: opts.ContentType,
+		Concurrency: opts.Concurrency,
+		Progress:    opts.Progress,
+	}, nil
+}
+
+// CiphertextLength returns the exact length of the message Encrypt produces for
+// plaintextLength bytes of plaintext. Defaults and options are the same as for Encrypt.
+//
+// The length depends on the encrypted data keys produced by the materials manager,
+// they can be taken from a previously encrypted message with [serialization.ParseHeader].
+//
+// Parameters:
+//   - plaintextLength int64: The length of the plaintext.
+//   - ec [suite.EncryptionContext]: The encryption context passed to Encrypt.
+//   - edks [serialization.EncryptedDataKeyInfo]: The encrypted data keys the materials manager produces.
+//   - optFns EncryptOptionFunc: A variadic set of optional functions for configuring encryption options.
+//
+// Returns:
+//   - int64: The length of the encrypted message.
+//   - error: An error if options are invalid or the message cannot be encrypted.
+//
+// Example usage:
+//
+//	info, _, err := serialization.ParseHeader(bytes.NewReader(previousCiphertext))
+//	if err != nil {
+//	    // handle error
+//	}
+//	size, err := client.CiphertextLength(int64(len(plaintext)), encryptionContext, info.EncryptedDataKeys,
+//	    WithFrameLength(1024))
+func (c *Client) CiphertextLength(plaintextLength int64, ec suite.EncryptionContext, edks []serialization.EncryptedDataKeyInfo, optFns ...EncryptOptionFunc) (int64, error) {
+	layout, err := c.messageLayout(ec, edks, optFns...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := serialization.CiphertextLength(plaintextLength, layout)
 	if err != nil {
-		return nil, nil, err
+		return 0, fmt.Errorf("SDK error: %w", errors.Join(crypto.ErrEncryption, err))
 	}
-	return ciphertext, header, nil
+	return n, nil
+}
+
+// MaxPlaintextLength returns the maximum plaintext length which Encrypt encrypts
+// into at most ciphertextLength bytes. Defaults and options are the same as for Encrypt.
+//
+// Parameters:
+//   - ciphertextLength int64: The byte budget for the encrypted message.
+//   - ec [suite.EncryptionContext]: The encryption context passed to Encrypt.
+//   - edks [serialization.EncryptedDataKeyInfo]: The encrypted data keys the materials manager produces.
+//   - optFns EncryptOptionFunc: A variadic set of optional functions for configuring encryption options.
+//
+// Returns:
+//   - int64: The maximum length of the plaintext.
+//   - error: An error if options are invalid or even empty plaintext does not fit.
+func (c *Client) MaxPlaintextLength(ciphertextLength int64, ec suite.EncryptionContext, edks []serialization.EncryptedDataKeyInfo, optFns ...EncryptOptionFunc) (int64, error) {
+	layout, err := c.messageLayout(ec, edks, optFns...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := serialization.MaxPlaintextLength(ciphertextLength, layout)
+	if err != nil {
+		return 0, fmt.Errorf("SDK error: %w", errors.Join(crypto.ErrEncryption, err))
+	}
+	return n, nil
+}
+
+func (c *Client) messageLayout(ec suite.EncryptionContext, edks []serialization.EncryptedDataKeyInfo, optFns ...EncryptOptionFunc) (serialization.MessageLayout, error) {
+	params, err := c.encryptParams(optFns...)
+	if err != nil {
+		return serialization.MessageLayout{}, err
+	}
+	return serialization.MessageLayout{
+		Algorithm:         params.Algorithm,
+		FrameLength:       params.FrameLength,
+		ContentType:       params.ContentType,
+		EncryptionContext: ec,
+		EncryptedDataKeys: edks,
+	}, nil
 }
 
 // Decrypt decrypts the given ciphertext using the provided materials manager.
 // It returns the decrypted plaintext and the message header.
 //
+// Decryption stops between frames once ctx is canceled or its deadline is exceeded,
+// the returned error wraps ctx.Err(). The same applies to DecryptTo, NewDecryptReader
+// and NewMessageIterator.
+//
 // Parameters:
 //
 //   - ctx: context.Context.
 //   - ciphertext []byte: The data to decrypt.
 //   - materialsManager [materials.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns DecryptOptionFunc: A variadic set of optional functions for configuring decryption options such as
+//     concurrency, required encryption context, allowed algorithm suites or keys.
 //
 // Returns:
 //
 //   - []byte: The decrypted data.
 //   - [serialization.MessageHeader]: The header of the encrypted message.
 //   - error: An error if decryption fails.
-func (c *Client) Decrypt(ctx context.Context, ciphertext []byte, materialsManager model.CryptoMaterialsManager) ([]byte, *serialization.MessageHeader, error) {
-	b, header, err := crypto.Decrypt(ctx, c.clientConfig(), ciphertext, materialsManager)
+func (c *Client) Decrypt(ctx context.Context, ciphertext []byte, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) ([]byte, *serialization.MessageHeader, error) {
+	params, err := decryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, header, err := crypto.DecryptWithParams(ctx, c.clientConfig(), ciphertext, materialsManager, params)
 	if err != nil {
 		return nil, nil, err
 	}
 	return b, header, nil
 }
+
+// DecryptTo decrypts ciphertext like Decrypt and appends the plaintext to dst.
+// Options are the same as for Decrypt.
+//
+// Plaintext is decrypted from ciphertext directly into dst, ciphertext is not copied
+// and not modified. If dst has enough spare capacity, at most len(ciphertext),
+// the plaintext is written in place, otherwise dst is grown like with append.
+// If the spare capacity of dst overlaps ciphertext, as with ciphertext[:0], the
+// plaintext is written to a newly allocated slice and ciphertext is left intact.
+// On error, dst content beyond its original length is undefined.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - dst []byte: The slice the plaintext is appended to, it may be nil.
+//   - ciphertext []byte: The data to decrypt.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns DecryptOptionFunc: A variadic set of optional functions for configuring decryption options.
+//
+// Returns:
+//   - []byte: The extended dst slice, holding the plaintext after the original dst content.
+//   - [serialization.MessageHeader]: The header of the encrypted message.
+//   - error: An error if decryption fails.
+//
+// Example usage:
+//
+//	buf := make([]byte, 0, len(ciphertext))
+//	plaintext, header, err := client.DecryptTo(context.TODO(), buf, ciphertext, materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+func (c *Client) DecryptTo(ctx context.Context, dst, ciphertext []byte, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) ([]byte, *serialization.MessageHeader, error) {
+	params, err := decryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.DecryptTo(ctx, c.clientConfig(), dst, ciphertext, materialsManager, params)
+}
+
+// NewDecryptReader returns an [io.ReadCloser] that reads the encrypted message from src
+// and yields plaintext frame by frame, without holding the whole message in memory.
+//
+// The message header is read from src and decryption materials are obtained
+// before NewDecryptReader returns. The reader returns [io.EOF] once the message
+// is complete, it never reads beyond the end of the message from src.
+//
+// For messages encrypted with a signing algorithm suite, plaintext is released only
+// after the footer signature is verified. It is held in memory up to DefaultBufferLimit,
+// use WithBufferLimit and WithSpillDir to change it, or WithSignedMessagePolicy to
+// release frames before the signature is verified.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - src io.Reader: The source of the encrypted message.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns DecryptOptionFunc: A variadic set of optional functions for configuring decryption options.
+//
+// Returns:
+//   - io.ReadCloser: The plaintext reader, Close does not close src.
+//   - [serialization.MessageHeader]: The header of the encrypted message.
+//   - error: An error if reading the header or obtaining materials fails.
+//
+// Example usage:
+//
+//	r, header, err := client.NewDecryptReader(context.TODO(), file, materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer r.Close()
+//	if _, err := io.Copy(dst, r); err != nil {
+//	    // handle error
+//	}
+func (c *Client) NewDecryptReader(ctx context.Context, src io.Reader, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (io.ReadCloser, *serialization.MessageHeader, error) {
+	params, err := decryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.NewDecryptReader(ctx, c.clientConfig(), src, materialsManager, params)
+}
+
+// NewMessageIterator returns a [crypto.MessageIterator] that decrypts consecutive
+// messages concatenated in src, one message per call to Next. Options apply to every message.
+//
+// Each message is read field by field and decrypted as a whole, Next returns its plaintext
+// only after the signature is verified. Next returns [io.EOF] once src has no more messages.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - src io.Reader: The source of the encrypted messages, it should be buffered.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns DecryptOptionFunc: A variadic set of optional functions for configuring decryption options.
+//
+// Returns:
+//   - [crypto.MessageIterator]: The message iterator.
+//   - error: An error if options are invalid.
+//
+// Example usage:
+//
+//	it, err := client.NewMessageIterator(context.TODO(), bufio.NewReader(file), materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+//	for {
+//	    plaintext, header, err := it.Next()
+//	    if errors.Is(err, io.EOF) {
+//	        break
+//	    }
+//	    if err != nil {
+//	        // handle error
+//	    }
+//	    // use plaintext and header
+//	}
+func (c *Client) NewMessageIterator(ctx context.Context, src io.Reader, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (*crypto.MessageIterator, error) {
+	params, err := decryptParams(optFns...)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewMessageIterator(ctx, c.clientConfig(), src, materialsManager, params)
+}
+
+// NewRangeDecrypter returns a [crypto.RangeDecrypter] that decrypts arbitrary plaintext ranges
+// of the message stored in src, such as an object served with HTTP Range requests.
+//
+// The message header and the final frame are read and authenticated, and decryption
+// materials are obtained before NewRangeDecrypter returns. Each ReadAt reads and decrypts only the frames covering
+// the requested range, the offset of each frame is computed from the frame length.
+//
+// The footer signature cannot be verified without reading the whole message, therefore only
+// framed messages encrypted with an algorithm suite without signing are supported, such as
+// [suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY]. Signed messages are rejected.
+//
+// The derived data key is held in memory until Close is called on the returned RangeDecrypter.
+//
+// ctx is kept for the lifetime of the RangeDecrypter, each ReadAt stops between frames
+// and fails once ctx is canceled or its deadline is exceeded.
+//
+// Parameters:
+//   - ctx context.Context: The context for the operation.
+//   - src io.ReaderAt: The source of the encrypted message.
+//   - size int64: The length of the encrypted message in src.
+//   - materialsManager [model.CryptoMaterialsManager]: The manager that provides the cryptographic materials.
+//   - optFns DecryptOptionFunc: A variadic set of optional functions for configuring decryption options.
+//
+// Returns:
+//   - [crypto.RangeDecrypter]: The plaintext [io.ReaderAt].
+//   - [serialization.MessageHeader]: The header of the encrypted message.
+//   - error: An error if the message is signed, reading the header or obtaining materials fails.
+//
+// Example usage:
+//
+//	rd, header, err := client.NewRangeDecrypter(context.TODO(), file, fileSize, materialsManager)
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer rd.Close()
+//	http.ServeContent(w, r, name, modTime, io.NewSectionReader(rd, 0, rd.Size()))
+func (c *Client) NewRangeDecrypter(ctx context.Context, src io.ReaderAt, size int64, materialsManager model.CryptoMaterialsManager, optFns ...DecryptOptionFunc) (*crypto.RangeDecrypter, *serialization.MessageHeader, error) {
+	params, err := decryptParams(optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crypto.NewRangeDecrypter(ctx, c.clientConfig(), src, size, materialsManager, params)
+}
+
+// decryptParams applies decrypt options over defaults.
+func decryptParams(optFns ...DecryptOptionFunc) (crypto.DecryptParams, error) {
+	opts := DecryptOptions{
+		Concurrency:         1,
+		SignedMessagePolicy: crypto.SignedMessagePolicyReleaseAfterVerify,
+		BufferLimit:         DefaultBufferLimit,
+	}
+	for _, optFn := range optFns {
+		if err := optFn(&opts); err != nil {
+			return crypto.DecryptParams{}, fmt.Errorf("invalid decrypt option: %w", errors.Join(crypto.ErrDecryption, err))
+		}
+	}
+	return crypto.DecryptParams{
+		Concurrency:               opts.Concurrency,
+		SignedMessagePolicy:       opts.SignedMessagePolicy,
+		BufferLimit:               opts.BufferLimit,
+		SpillDir:                  opts.SpillDir,
+		RequiredEncryptionContext: opts.RequiredEncryptionContext,
+		AllowedAlgorithms:         opts.AllowedAlgorithms,
+		UnsignedOnly:              opts.UnsignedOnly,
+		AllowedProviders:          opts.AllowedProviders,
+		AllowedKeyIDs:             opts.AllowedKeyIDs,
+		RejectTrailingBytes:       opts.RejectTrailingBytes,
+		Progress:                  opts.Progress,
+	}, nil
+}
@@ -4,13 +4,29 @@
 package client_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/client"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/helpers/bodyaad"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/materials"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/providers"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/providers/rawprovider"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/serialization"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/encryption"
 )
 
 func Test_NewClient(t *testing.T) {
@@ -29,3 +45,1569 @@ func Test_NewClientWithConfig(t *testing.T) {
 	assert.NotSame(t, *cl1, *cl2)
 	assert.Equal(t, fmt.Sprintf("%v", cl1), fmt.Sprintf("%v", cl2))
 }
+
+// newTestCMM returns default CMM with a raw provider of a single static key.
+func newTestCMM(t *testing.T) *materials.DefaultCryptoMaterialsManager {
+	t.Helper()
+	rawProvider, err := rawprovider.NewWithOpts(
+		"raw",
+		rawprovider.WithStaticKey("static1", []byte("superSecureKeySuperSecureKey1234")),
+	)
+	require.NoError(t, err)
+	cmm, err := materials.NewDefault(rawProvider)
+	require.NoError(t, err)
+	return cmm
+}
+
+func Test_Client_Encrypt_DefaultAlgorithm(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	tests := []struct {
+		policy suite.CommitmentPolicy
+		want   *suite.AlgorithmSuite
+	}{
+		{suite.CommitmentPolicyForbidEncryptAllowDecrypt, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384},
+		{suite.CommitmentPolicyRequireEncryptAllowDecrypt, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384},
+		{suite.CommitmentPolicyRequireEncryptRequireDecrypt, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			cfg, err := clientconfig.NewConfigWithOpts(clientconfig.WithCommitmentPolicy(tt.policy))
+			require.NoError(t, err)
+			c := client.NewClientWithConfig(cfg)
+
+			ciphertext, header, err := c.Encrypt(context.Background(), []byte("plaintext"), nil, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, header.AlgorithmSuite)
+
+			decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("plaintext"), decrypted)
+		})
+	}
+}
+
+func Test_Client_EncryptDecrypt_MessageFormatVersion(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+
+	plaintext := []byte("message format version plaintext, a bit longer than a frame")
+	ec := map[string]string{"purpose": "test"}
+
+	tests := []struct {
+		alg *suite.AlgorithmSuite
+	}{
+		{suite.AES_128_GCM_IV12_TAG16_NO_KDF},
+		{suite.AES_192_GCM_IV12_TAG16_NO_KDF},
+		{suite.AES_256_GCM_IV12_TAG16_NO_KDF},
+		{suite.AES_128_GCM_IV12_TAG16_HKDF_SHA256},
+		{suite.AES_192_GCM_IV12_TAG16_HKDF_SHA256},
+		{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256},
+		{suite.AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256},
+		{suite.AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384},
+		{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg.Name(), func(t *testing.T) {
+			ciphertext, header, err := c.Encrypt(context.Background(), plaintext, ec, cmm,
+				client.WithAlgorithm(tt.alg),
+				client.WithFrameLength(128),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, byte(suite.MessageFormatVersion1), ciphertext[0])
+			assert.Equal(t, tt.alg, header.AlgorithmSuite)
+			assert.Len(t, header.MessageID, tt.alg.MessageIDLen())
+
+			decrypted, decHeader, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+			assert.Equal(t, header.Bytes(), decHeader.Bytes())
+
+			// tampered header auth must fail
+			tampered := make([]byte, len(ciphertext))
+			copy(tampered, ciphertext)
+			tampered[header.Len()] ^= 0x01
+			_, _, err = c.Decrypt(context.Background(), tampered, cmm)
+			assert.ErrorIs(t, err, crypto.ErrDecryption)
+		})
+	}
+}
+
+func Test_Client_EncryptDecrypt_NonFramed(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyRequireEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+
+	legacyCfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	legacyClient := client.NewClientWithConfig(legacyCfg)
+
+	plaintext := []byte("non-framed single block plaintext")
+
+	tests := []struct {
+		name string
+		cl   *client.Client
+		alg  *suite.AlgorithmSuite
+	}{
+		{"COMMIT_KEY", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY},
+		{"COMMIT_KEY_ECDSA_P384", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384},
+		{"NO_KDF", legacyClient, suite.AES_128_GCM_IV12_TAG16_NO_KDF},
+		{"HKDF_SHA256", legacyClient, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256},
+		{"HKDF_SHA384_ECDSA_P384", legacyClient, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, header, err := tt.cl.Encrypt(context.Background(), plaintext, map[string]string{"purpose": "test"}, cmm,
+				client.WithAlgorithm(tt.alg),
+				client.WithContentType(suite.NonFramedContent),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, suite.NonFramedContent, header.ContentType())
+			assert.Equal(t, 0, header.FrameLength)
+
+			decrypted, decHeader, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+			assert.Equal(t, suite.NonFramedContent, decHeader.ContentType())
+
+			// tampered body content must fail
+			headerAuthLen := header.AlgorithmSuite.EncryptionSuite.AuthLen
+			if header.AlgorithmSuite.MessageFormatVersion == suite.MessageFormatVersion1 {
+				headerAuthLen += header.AlgorithmSuite.EncryptionSuite.IVLen
+			}
+			// header, header auth, body IV, 8 bytes content length
+			contentOffset := header.Len() + headerAuthLen + header.AlgorithmSuite.EncryptionSuite.IVLen + 8
+			tampered := make([]byte, len(ciphertext))
+			copy(tampered, ciphertext)
+			tampered[contentOffset] ^= 0x01
+			_, _, err = c.Decrypt(context.Background(), tampered, cmm)
+			assert.ErrorIs(t, err, crypto.ErrDecryption)
+		})
+	}
+}
+
+func Test_Client_Encrypt_InvalidContentType(t *testing.T) {
+	c := client.NewClient()
+	_, _, err := c.Encrypt(context.Background(), []byte("test"), nil, nil, client.WithContentType(suite.ContentType(3)))
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+}
+
+func Test_Client_NewEncryptWriter(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	ec := map[string]string{"purpose": "test"}
+
+	tests := []struct {
+		name        string
+		plaintext   []byte
+		chunkSize   int
+		contentType suite.ContentType
+	}{
+		{"single_chunk", bytes.Repeat([]byte("a"), 1000), 1000, suite.FramedContent},
+		{"small_chunks", bytes.Repeat([]byte("b"), 1000), 7, suite.FramedContent},
+		{"frame_aligned", bytes.Repeat([]byte("c"), 512), 128, suite.FramedContent},
+		{"large_chunks", bytes.Repeat([]byte("d"), 1000), 300, suite.FramedContent},
+		{"empty", []byte{}, 1, suite.FramedContent},
+		{"non_framed", bytes.Repeat([]byte("e"), 1000), 33, suite.NonFramedContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := new(bytes.Buffer)
+			w, header, err := c.NewEncryptWriter(context.Background(), dst, ec, cmm,
+				client.WithFrameLength(128),
+				client.WithContentType(tt.contentType),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, header.ContentType())
+			assert.Greater(t, dst.Len(), header.Len())
+
+			for p := tt.plaintext; len(p) > 0; {
+				n := tt.chunkSize
+				if n > len(p) {
+					n = len(p)
+				}
+				wn, err := w.Write(p[:n])
+				require.NoError(t, err)
+				assert.Equal(t, n, wn)
+				p = p[n:]
+			}
+			require.NoError(t, w.Close())
+
+			_, err = w.Write([]byte("late"))
+			assert.ErrorIs(t, err, crypto.ErrEncryption)
+			assert.ErrorIs(t, w.Close(), crypto.ErrEncryption)
+
+			decrypted, decHeader, err := c.Decrypt(context.Background(), dst.Bytes(), cmm)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, decrypted)
+			assert.Equal(t, header.Bytes(), decHeader.Bytes())
+		})
+	}
+}
+
+func Test_Client_NewEncryptWriter_InvalidOption(t *testing.T) {
+	c := client.NewClient()
+	_, _, err := c.NewEncryptWriter(context.Background(), new(bytes.Buffer), nil, nil, client.WithFrameLength(1))
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+}
+
+func Test_Client_NewDecryptReader(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyRequireEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+
+	plaintext := bytes.Repeat([]byte("streaming decryption plaintext "), 100)
+	ec := map[string]string{"purpose": "test"}
+	spillDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		alg         *suite.AlgorithmSuite
+		contentType suite.ContentType
+		opts        []client.DecryptOptionFunc
+	}{
+		{"signed_release_after_verify", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.FramedContent, nil},
+		{"signed_release_immediately", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.FramedContent, []client.DecryptOptionFunc{client.WithSignedMessagePolicy(crypto.SignedMessagePolicyReleaseImmediately)}},
+		{"signed_spill", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.FramedContent, []client.DecryptOptionFunc{client.WithBufferLimit(200), client.WithSpillDir(spillDir)}},
+		{"unsigned", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, suite.FramedContent, nil},
+		{"legacy_signed", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, suite.FramedContent, nil},
+		{"non_framed_signed", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.NonFramedContent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legacyCfg, err := clientconfig.NewConfigWithOpts(
+				clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+			)
+			require.NoError(t, err)
+			encClient := c
+			if !tt.alg.IsCommitting() {
+				encClient = client.NewClientWithConfig(legacyCfg)
+			}
+			ciphertext, header, err := encClient.Encrypt(context.Background(), plaintext, ec, cmm,
+				client.WithAlgorithm(tt.alg),
+				client.WithFrameLength(128),
+				client.WithContentType(tt.contentType),
+			)
+			require.NoError(t, err)
+
+			trailer := []byte("next")
+			src := bytes.NewReader(append(append([]byte{}, ciphertext...), trailer...))
+			r, decHeader, err := c.NewDecryptReader(context.Background(), src, cmm, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, header.Bytes(), decHeader.Bytes())
+
+			decrypted, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+			require.NoError(t, r.Close())
+
+			// reader stops at the end of the message
+			rest, err := io.ReadAll(src)
+			require.NoError(t, err)
+			assert.Equal(t, trailer, rest)
+		})
+	}
+
+	// spilled plaintext is removed
+	entries, err := os.ReadDir(spillDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_Client_NewDecryptReader_SignedMessagePolicy(t *testing.T) {
+	cmm := newTestCMM(t)
+	c := client.NewClient()
+
+	plaintext := bytes.Repeat([]byte("a"), 1000)
+	ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithFrameLength(128))
+	require.NoError(t, err)
+
+	// tampered signature
+	tampered := make([]byte, len(ciphertext))
+	copy(tampered, ciphertext)
+	tampered[len(tampered)-1] ^= 0x01
+
+	t.Run("release_after_verify", func(t *testing.T) {
+		r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(tampered), cmm)
+		require.NoError(t, err)
+		decrypted, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+		assert.Empty(t, decrypted)
+		require.NoError(t, r.Close())
+	})
+
+	t.Run("release_immediately", func(t *testing.T) {
+		r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(tampered), cmm,
+			client.WithSignedMessagePolicy(crypto.SignedMessagePolicyReleaseImmediately),
+		)
+		require.NoError(t, err)
+		decrypted, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("buffer_limit_exceeded", func(t *testing.T) {
+		r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm, client.WithBufferLimit(500))
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext[:len(ciphertext)-200]), cmm,
+			client.WithSignedMessagePolicy(crypto.SignedMessagePolicyReleaseImmediately),
+		)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+	})
+
+	t.Run("read_after_close", func(t *testing.T) {
+		r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		_, err = r.Read(make([]byte, 10))
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+	})
+
+	t.Run("invalid_option", func(t *testing.T) {
+		_, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm, client.WithBufferLimit(-1))
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+	})
+}
+
+func Test_Client_Encrypt_WithConcurrency(t *testing.T) {
+	cmm := newTestCMM(t)
+	c := client.NewClient()
+	ec := map[string]string{"purpose": "test"}
+
+	// 1000 full frames and a final frame
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 128*1000/16+5)
+
+	serial, _, err := c.Encrypt(context.Background(), plaintext, ec, cmm,
+		client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY),
+		client.WithFrameLength(128),
+	)
+	require.NoError(t, err)
+
+	for _, n := range []int{1, 2, 8, 64} {
+		t.Run(fmt.Sprintf("concurrency_%d", n), func(t *testing.T) {
+			ciphertext, _, err := c.Encrypt(context.Background(), plaintext, ec, cmm,
+				client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY),
+				client.WithFrameLength(128),
+				client.WithConcurrency(n),
+			)
+			require.NoError(t, err)
+			assert.Len(t, ciphertext, len(serial))
+
+			decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+
+			// signed message through the writer
+			dst := new(bytes.Buffer)
+			w, _, err := c.NewEncryptWriter(context.Background(), dst, ec, cmm,
+				client.WithFrameLength(128),
+				client.WithConcurrency(n),
+			)
+			require.NoError(t, err)
+			_, err = io.Copy(w, bytes.NewReader(plaintext))
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			decrypted, _, err = c.Decrypt(context.Background(), dst.Bytes(), cmm)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+		})
+	}
+
+	_, _, err = c.Encrypt(context.Background(), plaintext, ec, cmm, client.WithConcurrency(0))
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+}
+
+func Test_Client_Decrypt_WithDecryptConcurrency(t *testing.T) {
+	cmm := newTestCMM(t)
+	c := client.NewClient()
+	ec := map[string]string{"purpose": "test"}
+
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 128*500/16+5)
+
+	tests := []struct {
+		name string
+		alg  *suite.AlgorithmSuite
+	}{
+		{"signed", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384},
+		{"unsigned", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY},
+	}
+	for _, tt := range tests {
+		ciphertext, header, err := c.Encrypt(context.Background(), plaintext, ec, cmm,
+			client.WithAlgorithm(tt.alg),
+			client.WithFrameLength(128),
+		)
+		require.NoError(t, err)
+
+		for _, n := range []int{1, 4, 1000} {
+			t.Run(fmt.Sprintf("%s_concurrency_%d", tt.name, n), func(t *testing.T) {
+				decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cmm, client.WithDecryptConcurrency(n))
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+
+				// tampered frame in the middle of the body
+				tampered := make([]byte, len(ciphertext))
+				copy(tampered, ciphertext)
+				tampered[header.Len()+header.AlgorithmSuite.EncryptionSuite.AuthLen+250*(4+12+128+16)] ^= 0x01
+				_, _, err = c.Decrypt(context.Background(), tampered, cmm, client.WithDecryptConcurrency(n))
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+			})
+		}
+	}
+
+	_, _, err := c.Decrypt(context.Background(), []byte{0x02}, cmm, client.WithDecryptConcurrency(0))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_ParseHeader_EncryptedMessage(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	ciphertext, header, err := client.NewClient().Encrypt(context.Background(), []byte("plaintext"), map[string]string{"purpose": "test"}, cmm)
+	require.NoError(t, err)
+
+	info, n, err := serialization.ParseHeader(bytes.NewReader(ciphertext))
+	require.NoError(t, err)
+	assert.Equal(t, header.Len()+header.AlgorithmSuite.EncryptionSuite.AuthLen, n)
+	assert.Equal(t, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, info.Algorithm)
+	assert.Equal(t, "test", info.EncryptionContext["purpose"])
+	assert.Contains(t, info.EncryptionContext, "aws-crypto-public-key")
+	require.Len(t, info.EncryptedDataKeys, 1)
+	assert.Equal(t, "raw", info.EncryptedDataKeys[0].ProviderID)
+	assert.Equal(t, "static1", info.EncryptedDataKeys[0].KeyID)
+	assert.Equal(t, client.DefaultFrameLength, info.FrameLength)
+}
+
+func Test_Client_EncryptDecrypt_EmptyPlaintext(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	legacyCfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	legacyClient := client.NewClientWithConfig(legacyCfg)
+
+	tests := []struct {
+		name        string
+		cl          *client.Client
+		alg         *suite.AlgorithmSuite
+		contentType suite.ContentType
+	}{
+		{"framed_signed", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.FramedContent},
+		{"framed_unsigned", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, suite.FramedContent},
+		{"framed_v1", legacyClient, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, suite.FramedContent},
+		{"non_framed_signed", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.NonFramedContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, source := range [][]byte{nil, {}} {
+				ciphertext, header, err := tt.cl.Encrypt(context.Background(), source, nil, cmm,
+					client.WithAlgorithm(tt.alg),
+					client.WithContentType(tt.contentType),
+				)
+				require.NoError(t, err)
+
+				_, bodyOffset, err := serialization.ParseHeader(bytes.NewReader(ciphertext))
+				require.NoError(t, err)
+				ivLen := header.AlgorithmSuite.EncryptionSuite.IVLen
+				if tt.contentType == suite.FramedContent {
+					// single final frame with sequence number 1 and empty content
+					body := ciphertext[bodyOffset:]
+					assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x01}, body[:8])
+					assert.Equal(t, []byte{0x00, 0x00, 0x00, 0x00}, body[8+ivLen:8+ivLen+4])
+				}
+
+				decrypted, _, err := tt.cl.Decrypt(context.Background(), ciphertext, cmm)
+				require.NoError(t, err)
+				assert.Empty(t, decrypted)
+
+				r, _, err := tt.cl.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm)
+				require.NoError(t, err)
+				decrypted, err = io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Empty(t, decrypted)
+			}
+		})
+	}
+}
+
+func Test_Client_CiphertextLength(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	legacyCfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	legacyClient := client.NewClientWithConfig(legacyCfg)
+
+	ec := map[string]string{"purpose": "test", "department": "it"}
+	tests := []struct {
+		name        string
+		cl          *client.Client
+		alg         *suite.AlgorithmSuite
+		contentType suite.ContentType
+		ec          suite.EncryptionContext
+	}{
+		{"framed_signed_p384", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.FramedContent, ec},
+		{"framed_unsigned", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, suite.FramedContent, nil},
+		{"framed_v1_signed_p256", legacyClient, suite.AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256, suite.FramedContent, ec},
+		{"framed_v1_unsigned", legacyClient, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, suite.FramedContent, ec},
+		{"non_framed_signed", c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.NonFramedContent, ec},
+		{"non_framed_v1", legacyClient, suite.AES_128_GCM_IV12_TAG16_NO_KDF, suite.NonFramedContent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []client.EncryptOptionFunc{
+				client.WithAlgorithm(tt.alg),
+				client.WithContentType(tt.contentType),
+				client.WithFrameLength(128),
+			}
+			ciphertext, _, err := tt.cl.Encrypt(context.Background(), nil, tt.ec, cmm, opts...)
+			require.NoError(t, err)
+			info, _, err := serialization.ParseHeader(bytes.NewReader(ciphertext))
+			require.NoError(t, err)
+
+			for _, n := range []int{0, 1, 127, 128, 129, 256, 1000} {
+				ciphertext, _, err := tt.cl.Encrypt(context.Background(), bytes.Repeat([]byte{0x01}, n), tt.ec, cmm, opts...)
+				require.NoError(t, err)
+
+				size, err := tt.cl.CiphertextLength(int64(n), tt.ec, info.EncryptedDataKeys, opts...)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(ciphertext)), size, "plaintext length %d", n)
+
+				maxPlaintext, err := tt.cl.MaxPlaintextLength(size, tt.ec, info.EncryptedDataKeys, opts...)
+				require.NoError(t, err)
+				assert.Equal(t, int64(n), maxPlaintext, "ciphertext length %d", size)
+			}
+		})
+	}
+
+	_, err = c.CiphertextLength(10, nil, nil, client.WithFrameLength(0))
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+
+	_, err = c.MaxPlaintextLength(10, nil, nil)
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+}
+
+func Test_Client_Decrypt_WithRequiredEncryptionContext(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := []byte("plaintext")
+	ciphertext, _, err := c.Encrypt(context.Background(), plaintext, map[string]string{"purpose": "test", "department": "it"}, cmm)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		required map[string]string
+		wantErr  bool
+	}{
+		{"all_pairs", map[string]string{"purpose": "test", "department": "it"}, false},
+		{"subset", map[string]string{"purpose": "test"}, false},
+		{"missing_key", map[string]string{"purpose": "test", "owner": "alice"}, true},
+		{"different_value", map[string]string{"purpose": "prod"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decrypted, header, err := c.Decrypt(context.Background(), ciphertext, cmm, client.WithRequiredEncryptionContext(tt.required))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.ErrorContains(t, err, "encryption context mismatch")
+				assert.Nil(t, decrypted)
+				assert.Nil(t, header)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+
+			r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm, client.WithRequiredEncryptionContext(tt.required))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.Nil(t, r)
+			} else {
+				require.NoError(t, err)
+				decrypted, err = io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+		})
+	}
+
+	_, _, err = c.Decrypt(context.Background(), ciphertext, cmm, client.WithRequiredEncryptionContext(nil))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+type countingCMM struct {
+	model.CryptoMaterialsManager
+	encryptCalls int
+	decryptCalls int
+}
+
+func (c *countingCMM) GetEncryptionMaterials(ctx context.Context, request model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	c.encryptCalls++
+	return c.CryptoMaterialsManager.GetEncryptionMaterials(ctx, request)
+}
+
+func (c *countingCMM) DecryptMaterials(ctx context.Context, request model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	c.decryptCalls++
+	return c.CryptoMaterialsManager.DecryptMaterials(ctx, request)
+}
+
+func (c *countingCMM) GetInstance() model.CryptoMaterialsManager {
+	return c
+}
+
+func Test_Client_Decrypt_AllowedAlgorithms(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := []byte("plaintext")
+	signed, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384))
+	require.NoError(t, err)
+	unsigned, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		opts       []client.DecryptOptionFunc
+		wantErr    bool
+	}{
+		{"unsigned_only_unsigned", unsigned, []client.DecryptOptionFunc{client.WithUnsignedOnly()}, false},
+		{"unsigned_only_signed", signed, []client.DecryptOptionFunc{client.WithUnsignedOnly()}, true},
+		{"allowed_signed", signed, []client.DecryptOptionFunc{client.WithAllowedAlgorithms(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384)}, false},
+		{"not_allowed_unsigned", unsigned, []client.DecryptOptionFunc{client.WithAllowedAlgorithms(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384)}, true},
+		{"allowed_both", unsigned, []client.DecryptOptionFunc{client.WithAllowedAlgorithms(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counting := &countingCMM{CryptoMaterialsManager: cmm}
+			decrypted, _, err := c.Decrypt(context.Background(), tt.ciphertext, counting, tt.opts...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.ErrorContains(t, err, "algorithm suite not allowed")
+				assert.Equal(t, 0, counting.decryptCalls)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+				assert.Equal(t, 1, counting.decryptCalls)
+			}
+
+			_, _, err = c.NewDecryptReader(context.Background(), bytes.NewReader(tt.ciphertext), counting, tt.opts...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.Equal(t, 0, counting.decryptCalls)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	_, _, err = c.Decrypt(context.Background(), signed, cmm, client.WithAllowedAlgorithms())
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	_, _, err = c.Decrypt(context.Background(), signed, cmm, client.WithAllowedAlgorithms(nil))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_Decrypt_AllowedKeys(t *testing.T) {
+	rawProvider, err := rawprovider.NewWithOpts(
+		"raw",
+		rawprovider.WithStaticKey("static1", []byte("superSecureKeySuperSecureKey1234")),
+		rawprovider.WithStaticKey("static2", []byte("superSecureKeySuperSecureKey5678")),
+	)
+	require.NoError(t, err)
+	cmm, err := materials.NewDefault(rawProvider)
+	require.NoError(t, err)
+
+	c := client.NewClient()
+	plaintext := []byte("plaintext")
+	ciphertext, header, err := c.Encrypt(context.Background(), plaintext, nil, cmm)
+	require.NoError(t, err)
+	require.Len(t, header.EncryptedDataKeys, 2)
+
+	tests := []struct {
+		name    string
+		opts    []client.DecryptOptionFunc
+		wantErr bool
+	}{
+		{"allowed_provider", []client.DecryptOptionFunc{client.WithAllowedProviders("raw")}, false},
+		{"not_allowed_provider", []client.DecryptOptionFunc{client.WithAllowedProviders("aws-kms")}, true},
+		{"allowed_key_id", []client.DecryptOptionFunc{client.WithAllowedKeyIDs("static2")}, false},
+		{"allowed_key_id_pattern", []client.DecryptOptionFunc{client.WithAllowedKeyIDs("static*")}, false},
+		{"not_allowed_key_id", []client.DecryptOptionFunc{client.WithAllowedKeyIDs("arn:aws:kms:*:123456789012:key/*")}, true},
+		{"allowed_provider_not_allowed_key_id", []client.DecryptOptionFunc{client.WithAllowedProviders("raw"), client.WithAllowedKeyIDs("static3")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counting := &countingCMM{CryptoMaterialsManager: cmm}
+			decrypted, _, err := c.Decrypt(context.Background(), ciphertext, counting, tt.opts...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.ErrorContains(t, err, "no allowed encrypted data key")
+				assert.Equal(t, 0, counting.decryptCalls)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+		})
+	}
+
+	_, _, err = c.Decrypt(context.Background(), ciphertext, cmm, client.WithAllowedProviders())
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	_, _, err = c.Decrypt(context.Background(), ciphertext, cmm, client.WithAllowedProviders(""))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	_, _, err = c.Decrypt(context.Background(), ciphertext, cmm, client.WithAllowedKeyIDs("[static"))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_Decrypt_MaxDecryptAttempts(t *testing.T) {
+	wrappingKeys := map[string][]byte{
+		"static1": []byte("superSecureKeySuperSecureKey1234"),
+		"static2": []byte("superSecureKeySuperSecureKey5678"),
+		"static3": []byte("superSecureKeySuperSecureKey9012"),
+	}
+	encryptProvider, err := rawprovider.NewWithOpts(
+		"raw",
+		rawprovider.WithStaticKey("static1", wrappingKeys["static1"]),
+		rawprovider.WithStaticKey("static2", wrappingKeys["static2"]),
+		rawprovider.WithStaticKey("static3", wrappingKeys["static3"]),
+	)
+	require.NoError(t, err)
+	encryptCMM, err := materials.NewDefault(encryptProvider)
+	require.NoError(t, err)
+
+	ciphertext, header, err := client.NewClient().Encrypt(context.Background(), []byte("plaintext"), nil, encryptCMM)
+	require.NoError(t, err)
+
+	// encrypted data keys are tried in message order, only the wrapping key
+	// of the last one is correct, so decryption takes exactly 3 attempts
+	edks := serialization.EDK.AsKeys(header.EncryptedDataKeys)
+	require.Len(t, edks, 3)
+	var decryptOpts []func(*rawprovider.Options) error
+	for i, edk := range edks {
+		key := []byte("otherSecureKeyOtherSecureKey1234")
+		if i == len(edks)-1 {
+			key = wrappingKeys[edk.KeyID()]
+		}
+		decryptOpts = append(decryptOpts, rawprovider.WithStaticKey(edk.KeyID(), key))
+	}
+	decryptProvider, err := rawprovider.NewWithOpts("raw", decryptOpts...)
+	require.NoError(t, err)
+	decryptCMM, err := materials.NewDefault(decryptProvider)
+	require.NoError(t, err)
+
+	for _, limit := range []int{1, 2} {
+		cfg, err := clientconfig.NewConfigWithOpts(clientconfig.WithMaxDecryptAttempts(limit))
+		require.NoError(t, err)
+		_, _, err = client.NewClientWithConfig(cfg).Decrypt(context.Background(), ciphertext, decryptCMM)
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+		assert.ErrorIs(t, err, providers.ErrMaxDecryptAttempts)
+		assert.ErrorContains(t, err, fmt.Sprintf("%d decrypt attempts skipped", len(edks)-limit))
+	}
+
+	cfg, err := clientconfig.NewConfigWithOpts(clientconfig.WithMaxDecryptAttempts(len(edks)))
+	require.NoError(t, err)
+	decrypted, _, err := client.NewClientWithConfig(cfg).Decrypt(context.Background(), ciphertext, decryptCMM)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext"), decrypted)
+}
+
+func Test_Client_NewMessageIterator(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintexts := [][]byte{
+		[]byte("first batch"),
+		bytes.Repeat([]byte("second batch"), 1000),
+		{},
+		[]byte("fourth batch"),
+	}
+	algorithms := []*suite.AlgorithmSuite{
+		suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384,
+		suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384,
+		suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+	}
+	var stream []byte
+	for i, plaintext := range plaintexts {
+		ciphertext, _, err := c.Encrypt(context.Background(), plaintext, map[string]string{"batch": fmt.Sprint(i)}, cmm,
+			client.WithAlgorithm(algorithms[i]),
+			client.WithFrameLength(1024),
+		)
+		require.NoError(t, err)
+		stream = append(stream, ciphertext...)
+	}
+
+	it, err := c.NewMessageIterator(context.Background(), bytes.NewReader(stream), cmm)
+	require.NoError(t, err)
+	for i, want := range plaintexts {
+		got, header, err := it.Next()
+		require.NoError(t, err)
+		assert.Equal(t, want, append([]byte{}, got...))
+		assert.Equal(t, fmt.Sprint(i), header.AADData.AsEncryptionContext()["batch"])
+		assert.Equal(t, algorithms[i], header.AlgorithmSuite)
+	}
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// truncated last message
+	it, err = c.NewMessageIterator(context.Background(), bytes.NewReader(stream[:len(stream)-10]), cmm)
+	require.NoError(t, err)
+	for range plaintexts[:3] {
+		_, _, err = it.Next()
+		require.NoError(t, err)
+	}
+	_, _, err = it.Next()
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, _, errSticky := it.Next()
+	assert.Equal(t, err, errSticky)
+
+	_, err = c.NewMessageIterator(context.Background(), nil, cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_Decrypt_WithRejectTrailingBytes(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	for _, alg := range []*suite.AlgorithmSuite{suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY} {
+		ciphertext, _, err := c.Encrypt(context.Background(), []byte("plaintext"), nil, cmm, client.WithAlgorithm(alg))
+		require.NoError(t, err)
+
+		decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cmm, client.WithRejectTrailingBytes())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("plaintext"), decrypted)
+
+		concatenated := append(append([]byte{}, ciphertext...), ciphertext...)
+		_, _, err = c.Decrypt(context.Background(), concatenated, cmm, client.WithRejectTrailingBytes())
+		assert.ErrorIs(t, err, crypto.ErrDecryption)
+	}
+
+	// trailing bytes of unsigned messages are ignored unless rejected
+	ciphertext, _, err := c.Encrypt(context.Background(), []byte("plaintext"), nil, cmm, client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY))
+	require.NoError(t, err)
+	concatenated := append(append([]byte{}, ciphertext...), ciphertext...)
+	decrypted, _, err := c.Decrypt(context.Background(), concatenated, cmm)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext"), decrypted)
+
+	_, _, err = c.Decrypt(context.Background(), concatenated, cmm, client.WithRejectTrailingBytes())
+	assert.ErrorIs(t, err, crypto.ErrInvalidMessage)
+}
+
+func Test_Client_NewRangeDecrypter(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	frameLength := 128
+	for _, plaintextLen := range []int{0, 1, 127, 128, 129, 1000, 1024} {
+		t.Run(fmt.Sprint(plaintextLen), func(t *testing.T) {
+			plaintext := make([]byte, plaintextLen)
+			for i := range plaintext {
+				plaintext[i] = byte(i)
+			}
+			ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm,
+				client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY),
+				client.WithFrameLength(frameLength),
+			)
+			require.NoError(t, err)
+
+			rd, header, err := c.NewRangeDecrypter(context.Background(), bytes.NewReader(ciphertext), int64(len(ciphertext)), cmm, client.WithDecryptConcurrency(2))
+			require.NoError(t, err)
+			assert.Equal(t, frameLength, header.FrameLength)
+			assert.Equal(t, int64(plaintextLen), rd.Size())
+
+			ranges := [][2]int{{0, plaintextLen}, {0, 1}, {plaintextLen / 2, plaintextLen}, {100, 300}, {127, 129}, {plaintextLen - 1, plaintextLen}}
+			for _, r := range ranges {
+				from, to := r[0], r[1]
+				if from < 0 || to > plaintextLen || from >= to {
+					continue
+				}
+				p := make([]byte, to-from)
+				n, err := rd.ReadAt(p, int64(from))
+				require.NoError(t, err)
+				assert.Equal(t, to-from, n)
+				assert.Equal(t, plaintext[from:to], p)
+			}
+
+			// read beyond the end
+			p := make([]byte, 10)
+			n, err := rd.ReadAt(p, int64(plaintextLen)-5)
+			if plaintextLen >= 5 {
+				assert.ErrorIs(t, err, io.EOF)
+				assert.Equal(t, 5, n)
+				assert.Equal(t, plaintext[plaintextLen-5:], p[:n])
+			}
+			_, err = rd.ReadAt(p, int64(plaintextLen))
+			assert.ErrorIs(t, err, io.EOF)
+
+			// whole plaintext through a section reader
+			decrypted, err := io.ReadAll(io.NewSectionReader(rd, 0, rd.Size()))
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+
+			// wrong message size
+			_, _, err = c.NewRangeDecrypter(context.Background(), bytes.NewReader(ciphertext), int64(len(ciphertext))-1, cmm)
+			assert.ErrorIs(t, err, crypto.ErrDecryption)
+			extended := append(append([]byte{}, ciphertext...), make([]byte, frameLength+100)...)
+			_, _, err = c.NewRangeDecrypter(context.Background(), bytes.NewReader(extended), int64(len(extended)), cmm)
+			assert.ErrorIs(t, err, crypto.ErrDecryption)
+		})
+	}
+}
+
+func Test_Client_NewRangeDecrypter_FullFinalFrame(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	legacyCfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(legacyCfg)
+
+	// NO_KDF suite encrypts frames with the data key itself
+	alg := suite.AES_256_GCM_IV12_TAG16_NO_KDF
+	frameLength := 128
+	plaintext := make([]byte, 2*frameLength)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+	ciphertext, header, err := c.Encrypt(context.Background(), plaintext, nil, cmm,
+		client.WithAlgorithm(alg),
+		client.WithFrameLength(frameLength),
+	)
+	require.NoError(t, err)
+
+	// replace the second regular frame and the empty final frame
+	// with the final frame carrying exactly frame length bytes
+	regular, final := serialization.FrameOverheads(alg)
+	secondFrame := len(ciphertext) - int(final) - int(regular) - frameLength
+	iv := ciphertext[secondFrame+4 : secondFrame+4+alg.EncryptionSuite.IVLen]
+
+	dm, err := cmm.DecryptMaterials(context.Background(), model.DecryptionMaterialsRequest{
+		Algorithm:         alg,
+		EncryptedDataKeys: serialization.EDK.AsKeys(header.EncryptedDataKeys),
+		EncryptionContext: header.AADData.AsEncryptionContext(),
+	})
+	require.NoError(t, err)
+	contentString, err := bodyaad.BodyAAD.ContentString(suite.FramedContent, true)
+	require.NoError(t, err)
+	aad := bodyaad.BodyAAD.ContentAADBytes(header.MessageID, contentString, 2, frameLength)
+	sealed, tag, err := encryption.Gcm{}.Encrypt(dm.DataKey().DataKey(), iv, plaintext[frameLength:], aad)
+	require.NoError(t, err)
+
+	message := append([]byte(nil), ciphertext[:secondFrame]...)
+	message = binary.BigEndian.AppendUint32(message, 0xFFFFFFFF) // final frame sequence number end
+	message = binary.BigEndian.AppendUint32(message, 2)
+	message = append(message, iv...)
+	message = binary.BigEndian.AppendUint32(message, uint32(frameLength))
+	message = append(message, sealed...)
+	message = append(message, tag...)
+	require.Len(t, message, len(ciphertext)-int(regular))
+
+	decrypted, _, err := c.Decrypt(context.Background(), message, cmm)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	rd, _, err := c.NewRangeDecrypter(context.Background(), bytes.NewReader(message), int64(len(message)), cmm)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(plaintext)), rd.Size())
+	got, err := io.ReadAll(io.NewSectionReader(rd, 0, rd.Size()))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	p := make([]byte, 10)
+	n, err := rd.ReadAt(p, int64(frameLength+100))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext[frameLength+100:frameLength+110], p[:n])
+}
+
+func Test_Client_NewRangeDecrypter_Errors(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	signed, _, err := c.Encrypt(context.Background(), []byte("plaintext"), nil, cmm)
+	require.NoError(t, err)
+	_, _, err = c.NewRangeDecrypter(context.Background(), bytes.NewReader(signed), int64(len(signed)), cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	assert.ErrorContains(t, err, "signature cannot be verified")
+
+	nonFramed, _, err := c.Encrypt(context.Background(), []byte("plaintext"), nil, cmm,
+		client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY),
+		client.WithContentType(suite.NonFramedContent),
+	)
+	require.NoError(t, err)
+	_, _, err = c.NewRangeDecrypter(context.Background(), bytes.NewReader(nonFramed), int64(len(nonFramed)), cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+
+	_, _, err = c.NewRangeDecrypter(context.Background(), nil, 0, cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_EncryptTo_DecryptTo_Overlap(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	ec := map[string]string{"purpose": "test"}
+	opts := []client.EncryptOptionFunc{client.WithFrameLength(128)}
+	plaintext := bytes.Repeat([]byte{0x01, 0x02}, 500)
+
+	// dst spare capacity overlaps source
+	buf := make([]byte, len(plaintext), 4*len(plaintext))
+	copy(buf, plaintext)
+	source := buf[:len(plaintext)]
+	ciphertext, _, err := c.EncryptTo(context.Background(), buf[:0], source, ec, cmm, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, source)
+
+	got, _, err := c.Decrypt(context.Background(), ciphertext, cmm)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	// dst spare capacity overlaps ciphertext
+	sealed := append([]byte(nil), ciphertext...)
+	opened, _, err := c.DecryptTo(context.Background(), sealed[:0], sealed, cmm)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+	assert.Equal(t, ciphertext, sealed)
+}
+
+func Test_Client_EncryptTo_DecryptTo(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	ec := map[string]string{"purpose": "test"}
+	prefix := []byte("prefix")
+	tests := []struct {
+		name string
+		opts []client.EncryptOptionFunc
+		dec  []client.DecryptOptionFunc
+	}{
+		{"framed_signed", []client.EncryptOptionFunc{client.WithFrameLength(128)}, nil},
+		{"framed_unsigned", []client.EncryptOptionFunc{client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY), client.WithFrameLength(128)}, nil},
+		{"framed_concurrent", []client.EncryptOptionFunc{client.WithFrameLength(128), client.WithConcurrency(4)}, []client.DecryptOptionFunc{client.WithDecryptConcurrency(4)}},
+		{"non_framed", []client.EncryptOptionFunc{client.WithContentType(suite.NonFramedContent)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 128, 1000} {
+				plaintext := bytes.Repeat([]byte{0x01}, n)
+
+				ciphertext, _, err := c.EncryptTo(context.Background(), append([]byte(nil), prefix...), plaintext, ec, cmm, tt.opts...)
+				require.NoError(t, err)
+				require.True(t, bytes.HasPrefix(ciphertext, prefix))
+				ciphertext = ciphertext[len(prefix):]
+
+				info, _, err := serialization.ParseHeader(bytes.NewReader(ciphertext))
+				require.NoError(t, err)
+				size, err := c.CiphertextLength(int64(n), ec, info.EncryptedDataKeys, tt.opts...)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(ciphertext)), size)
+
+				want, _, err := c.Decrypt(context.Background(), ciphertext, cmm, tt.dec...)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, want)
+
+				got, _, err := c.DecryptTo(context.Background(), append([]byte(nil), prefix...), ciphertext, cmm, tt.dec...)
+				require.NoError(t, err)
+				assert.Equal(t, append(append([]byte(nil), prefix...), plaintext...), got)
+
+				// presized dst is filled in place
+				dst := make([]byte, 0, size)
+				sealed, _, err := c.EncryptTo(context.Background(), dst, plaintext, ec, cmm, tt.opts...)
+				require.NoError(t, err)
+				assert.Len(t, sealed, int(size))
+				assert.Same(t, &dst[:1][0], &sealed[0])
+
+				out := make([]byte, 0, len(sealed))
+				opened, _, err := c.DecryptTo(context.Background(), out, sealed, cmm, tt.dec...)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, opened)
+				if n > 0 {
+					assert.Same(t, &out[:1][0], &opened[0])
+				}
+			}
+		})
+	}
+
+	ciphertext, _, err := c.Encrypt(context.Background(), []byte("secret"), ec, cmm)
+	require.NoError(t, err)
+	ciphertext[len(ciphertext)/2] ^= 0x01
+	_, _, err = c.DecryptTo(context.Background(), nil, ciphertext, cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_DecryptTo_WipesPlaintextOnFailure(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := bytes.Repeat([]byte{0xAB}, 1000)
+	prefix := []byte("prefix")
+
+	tests := []struct {
+		name   string
+		opts   []client.EncryptOptionFunc
+		dec    []client.DecryptOptionFunc
+		tamper func(ciphertext []byte) []byte
+	}{
+		{"signature", []client.EncryptOptionFunc{client.WithFrameLength(128)}, nil, func(ciphertext []byte) []byte {
+			ciphertext[len(ciphertext)-1] ^= 0x01
+			return ciphertext
+		}},
+		{"signature_non_framed", []client.EncryptOptionFunc{client.WithContentType(suite.NonFramedContent)}, nil, func(ciphertext []byte) []byte {
+			ciphertext[len(ciphertext)-1] ^= 0x01
+			return ciphertext
+		}},
+		{"truncated_footer", []client.EncryptOptionFunc{client.WithFrameLength(128)}, nil, func(ciphertext []byte) []byte {
+			return ciphertext[:len(ciphertext)-10]
+		}},
+		{"trailing_bytes", []client.EncryptOptionFunc{client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY), client.WithFrameLength(128)}, []client.DecryptOptionFunc{client.WithRejectTrailingBytes()}, func(ciphertext []byte) []byte {
+			return append(ciphertext, 0x00)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, tt.opts...)
+			require.NoError(t, err)
+			ciphertext = tt.tamper(ciphertext)
+
+			dst := make([]byte, len(prefix), len(prefix)+len(plaintext))
+			copy(dst, prefix)
+			opened, _, err := c.DecryptTo(context.Background(), dst, ciphertext, cmm, tt.dec...)
+			assert.ErrorIs(t, err, crypto.ErrDecryption)
+			assert.Nil(t, opened)
+
+			assert.Equal(t, prefix, dst)
+			assert.Equal(t, make([]byte, len(plaintext)), dst[len(dst):cap(dst)])
+		})
+	}
+}
+
+// destroyTrackingCMM records materials it returns and counts their Destroy calls.
+type destroyTrackingCMM struct {
+	model.CryptoMaterialsManager
+	encMaterials []*trackedEncryptionMaterial
+	decMaterials []*trackedDecryptionMaterial
+}
+
+type trackedEncryptionMaterial struct {
+	model.EncryptionMaterial
+	destroyed int
+}
+
+func (m *trackedEncryptionMaterial) Destroy() {
+	m.destroyed++
+	if d, ok := m.EncryptionMaterial.(model.Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+type trackedDecryptionMaterial struct {
+	model.DecryptionMaterial
+	destroyed int
+}
+
+func (m *trackedDecryptionMaterial) Destroy() {
+	m.destroyed++
+	if d, ok := m.DecryptionMaterial.(model.Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+func (c *destroyTrackingCMM) GetEncryptionMaterials(ctx context.Context, request model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	m, err := c.CryptoMaterialsManager.GetEncryptionMaterials(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedEncryptionMaterial{EncryptionMaterial: m}
+	c.encMaterials = append(c.encMaterials, tracked)
+	return tracked, nil
+}
+
+func (c *destroyTrackingCMM) DecryptMaterials(ctx context.Context, request model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	m, err := c.CryptoMaterialsManager.DecryptMaterials(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedDecryptionMaterial{DecryptionMaterial: m}
+	c.decMaterials = append(c.decMaterials, tracked)
+	return tracked, nil
+}
+
+func (c *destroyTrackingCMM) GetInstance() model.CryptoMaterialsManager {
+	return c
+}
+
+func Test_Client_DestroysMaterials(t *testing.T) {
+	defaultCMM := newTestCMM(t)
+	cmm := &destroyTrackingCMM{CryptoMaterialsManager: defaultCMM}
+
+	c := client.NewClient()
+	plaintext := bytes.Repeat([]byte{0x01}, 1000)
+
+	assertEncDestroyed := func(t *testing.T) {
+		t.Helper()
+		m := cmm.encMaterials[len(cmm.encMaterials)-1]
+		assert.Equal(t, 1, m.destroyed)
+		assert.Equal(t, make([]byte, 32), m.DataEncryptionKey().DataKey())
+		if m.SigningKey() != nil {
+			assert.Zero(t, m.SigningKey().D.Sign())
+		}
+	}
+	assertDecDestroyed := func(t *testing.T) {
+		t.Helper()
+		m := cmm.decMaterials[len(cmm.decMaterials)-1]
+		assert.Equal(t, 1, m.destroyed)
+		assert.Equal(t, make([]byte, 32), m.DataKey().DataKey())
+	}
+
+	ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithFrameLength(128))
+	require.NoError(t, err)
+	assertEncDestroyed(t)
+
+	var buf bytes.Buffer
+	w, _, err := c.NewEncryptWriter(context.Background(), &buf, nil, cmm)
+	require.NoError(t, err)
+	assert.Zero(t, cmm.encMaterials[len(cmm.encMaterials)-1].destroyed)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assertEncDestroyed(t)
+
+	got, _, err := c.Decrypt(context.Background(), ciphertext, cmm)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+	assertDecDestroyed(t)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-200] ^= 0x01
+	_, _, err = c.Decrypt(context.Background(), tampered, cmm)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	assertDecDestroyed(t)
+
+	r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), cmm)
+	require.NoError(t, err)
+	assertDecDestroyed(t)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+	require.NoError(t, r.Close())
+
+	unsigned, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithAlgorithm(suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY))
+	require.NoError(t, err)
+	rd, _, err := c.NewRangeDecrypter(context.Background(), bytes.NewReader(unsigned), int64(len(unsigned)), cmm)
+	require.NoError(t, err)
+	assertDecDestroyed(t)
+	p := make([]byte, 10)
+	_, err = rd.ReadAt(p, 500)
+	require.NoError(t, err)
+	require.NoError(t, rd.Close())
+	_, err = rd.ReadAt(p, 500)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_ContextCancellation(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := bytes.Repeat([]byte{0x01}, 1000)
+	ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithFrameLength(128))
+	require.NoError(t, err)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), 0)
+	defer cancelExpired()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"canceled", canceled, context.Canceled},
+		{"deadline_exceeded", expired, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, concurrency := range []int{1, 4} {
+				_, _, err := c.Encrypt(tt.ctx, plaintext, nil, cmm, client.WithFrameLength(128), client.WithConcurrency(concurrency))
+				assert.ErrorIs(t, err, crypto.ErrEncryption)
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				_, _, err = c.Decrypt(tt.ctx, ciphertext, cmm, client.WithDecryptConcurrency(concurrency))
+				assert.ErrorIs(t, err, crypto.ErrDecryption)
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	// streaming stops once the context is canceled after start
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	w, _, err := c.NewEncryptWriter(ctx, &buf, nil, cmm, client.WithFrameLength(128))
+	require.NoError(t, err)
+	_, err = w.Write(plaintext[:256])
+	require.NoError(t, err)
+	cancel()
+	_, err = w.Write(plaintext[256:])
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	r, _, err := c.NewDecryptReader(ctx, bytes.NewReader(ciphertext), cmm, client.WithSignedMessagePolicy(crypto.SignedMessagePolicyReleaseImmediately))
+	require.NoError(t, err)
+	_, err = r.Read(make([]byte, 128))
+	require.NoError(t, err)
+	cancel()
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Client_Progress(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := bytes.Repeat([]byte{0x01}, 1000)
+
+	// 7 full frames of 128 bytes and the final frame of 104 bytes
+	assertFrames := func(t *testing.T, got []crypto.Progress, total int64) {
+		t.Helper()
+		require.Len(t, got, 8)
+		for i, p := range got {
+			assert.Equal(t, i+1, p.SequenceNumber)
+			assert.Equal(t, total, p.Total)
+		}
+		assert.Equal(t, int64(128), got[0].Bytes)
+		assert.Equal(t, int64(1000), got[7].Bytes)
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("concurrency_%d", concurrency), func(t *testing.T) {
+			var encrypted []crypto.Progress
+			ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm,
+				client.WithFrameLength(128),
+				client.WithConcurrency(concurrency),
+				client.WithProgress(func(p crypto.Progress) { encrypted = append(encrypted, p) }))
+			require.NoError(t, err)
+			assertFrames(t, encrypted, 1000)
+
+			var decrypted []crypto.Progress
+			_, _, err = c.Decrypt(context.Background(), ciphertext, cmm,
+				client.WithDecryptConcurrency(concurrency),
+				client.WithDecryptProgress(func(p crypto.Progress) { decrypted = append(decrypted, p) }))
+			require.NoError(t, err)
+			assertFrames(t, decrypted, 1000)
+		})
+	}
+
+	t.Run("streaming", func(t *testing.T) {
+		var encrypted []crypto.Progress
+		var buf bytes.Buffer
+		w, _, err := c.NewEncryptWriter(context.Background(), &buf, nil, cmm,
+			client.WithFrameLength(128),
+			client.WithProgress(func(p crypto.Progress) { encrypted = append(encrypted, p) }))
+		require.NoError(t, err)
+		_, err = w.Write(plaintext)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assertFrames(t, encrypted, -1)
+
+		var decrypted []crypto.Progress
+		r, _, err := c.NewDecryptReader(context.Background(), &buf, cmm,
+			client.WithDecryptProgress(func(p crypto.Progress) { decrypted = append(decrypted, p) }))
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		require.NoError(t, err)
+		assertFrames(t, decrypted, -1)
+	})
+
+	t.Run("non_framed", func(t *testing.T) {
+		var got []crypto.Progress
+		ciphertext, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm,
+			client.WithContentType(suite.NonFramedContent),
+			client.WithProgress(func(p crypto.Progress) { got = append(got, p) }))
+		require.NoError(t, err)
+		_, _, err = c.Decrypt(context.Background(), ciphertext, cmm,
+			client.WithDecryptProgress(func(p crypto.Progress) { got = append(got, p) }))
+		require.NoError(t, err)
+		want := crypto.Progress{SequenceNumber: 1, Bytes: 1000, Total: 1000}
+		assert.Equal(t, []crypto.Progress{want, want}, got)
+	})
+
+	_, _, err := c.Encrypt(context.Background(), plaintext, nil, cmm, client.WithProgress(nil))
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+	_, _, err = c.Decrypt(context.Background(), nil, cmm, client.WithDecryptProgress(nil))
+	assert.ErrorIs(t, err, crypto.ErrDecryption)
+}
+
+func Test_Client_CachingCMM(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	c := client.NewClient()
+	plaintext := []byte("plaintext")
+	ec := map[string]string{"purpose": "test"}
+
+	tests := []struct {
+		name        string
+		algorithm   *suite.AlgorithmSuite
+		wantEncrypt int
+	}{
+		{"non_signing", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, 1},
+		{"signing", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := materials.NewMemoryCache(10, time.Minute)
+			require.NoError(t, err)
+			counting := &countingCMM{CryptoMaterialsManager: cmm}
+			cachingCMM, err := materials.NewCaching(cache, counting, materials.WithMaxMessagesEncrypted(10))
+			require.NoError(t, err)
+
+			var ciphertexts [][]byte
+			for i := 0; i < 3; i++ {
+				ciphertext, _, err := c.Encrypt(context.Background(), plaintext, ec, cachingCMM, client.WithAlgorithm(tt.algorithm))
+				require.NoError(t, err)
+				ciphertexts = append(ciphertexts, ciphertext)
+			}
+			assert.Equal(t, tt.wantEncrypt, counting.encryptCalls)
+
+			for _, ciphertext := range ciphertexts {
+				decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cachingCMM)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+
+				// cached materials are never used up by message zeroization
+				decrypted, _, err = c.Decrypt(context.Background(), ciphertext, cmm)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+			assert.Equal(t, tt.wantEncrypt, counting.decryptCalls)
+		})
+	}
+}
+
+func Test_Client_RequiredEncryptionContextCMM(t *testing.T) {
+	cmm := newTestCMM(t)
+	requiredCMM, err := materials.NewRequiredEncryptionContext(cmm, "tenant")
+	require.NoError(t, err)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyRequireEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+	// message format version 1 is encrypted with non-committing algorithm suite
+	v1Cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyForbidEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	v1Client := client.NewClientWithConfig(v1Cfg)
+	plaintext := []byte("plaintext")
+
+	_, _, err = c.Encrypt(context.Background(), plaintext, map[string]string{"purpose": "test"}, requiredCMM)
+	assert.ErrorIs(t, err, crypto.ErrEncryption)
+	assert.ErrorIs(t, err, materials.ErrCMM)
+
+	for _, v := range []struct {
+		c   *client.Client
+		alg *suite.AlgorithmSuite
+	}{
+		{c, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384},
+		{v1Client, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256},
+	} {
+		c, alg := v.c, v.alg
+		t.Run(alg.Name(), func(t *testing.T) {
+			ciphertext, header, err := c.Encrypt(context.Background(), plaintext, map[string]string{"purpose": "test", "tenant": "alice"}, requiredCMM, client.WithAlgorithm(alg))
+			require.NoError(t, err)
+			// required key is not stored in the header
+			assert.NotContains(t, header.AADData.AsEncryptionContext(), "tenant")
+			assert.Contains(t, header.AADData.AsEncryptionContext(), "purpose")
+
+			tests := []struct {
+				name     string
+				cmm      model.CryptoMaterialsManager
+				required map[string]string
+				wantErr  bool
+			}{
+				{"reproduced", requiredCMM, map[string]string{"tenant": "alice"}, false},
+				{"reproduced_with_stored", requiredCMM, map[string]string{"tenant": "alice", "purpose": "test"}, false},
+				{"not_reproduced", requiredCMM, nil, true},
+				{"wrong_value", requiredCMM, map[string]string{"tenant": "bob"}, true},
+				{"default_cmm", cmm, map[string]string{"tenant": "alice"}, true},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var opts []client.DecryptOptionFunc
+					if tt.required != nil {
+						opts = append(opts, client.WithRequiredEncryptionContext(tt.required))
+					}
+					decrypted, _, err := c.Decrypt(context.Background(), ciphertext, tt.cmm, opts...)
+					if tt.wantErr {
+						assert.ErrorIs(t, err, crypto.ErrDecryption)
+						assert.Nil(t, decrypted)
+						return
+					}
+					require.NoError(t, err)
+					assert.Equal(t, plaintext, decrypted)
+
+					r, _, err := c.NewDecryptReader(context.Background(), bytes.NewReader(ciphertext), tt.cmm, opts...)
+					require.NoError(t, err)
+					decrypted, err = io.ReadAll(r)
+					require.NoError(t, err)
+					assert.Equal(t, plaintext, decrypted)
+				})
+			}
+		})
+	}
+}
+
+func Test_Client_PoolingCMM(t *testing.T) {
+	cmm := newTestCMM(t)
+	poolingCMM, err := materials.NewPooling(cmm, materials.WithPoolSize(2))
+	require.NoError(t, err)
+	defer poolingCMM.Close()
+
+	c := client.NewClient()
+	plaintext := []byte("plaintext")
+	ec := map[string]string{"purpose": "test"}
+
+	edks := make(map[string]struct{})
+	for i := 0; i < 5; i++ {
+		ciphertext, header, err := c.Encrypt(context.Background(), plaintext, ec, poolingCMM)
+		require.NoError(t, err)
+		// each message has its own data key
+		edk := string(serialization.EDK.AsKeys(header.EncryptedDataKeys)[0].EncryptedDataKey())
+		assert.NotContains(t, edks, edk)
+		edks[edk] = struct{}{}
+
+		decrypted, _, err := c.Decrypt(context.Background(), ciphertext, cmm)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/client"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// Known answer messages are encrypted by the AWS Encryption SDK for Go v0.4.0
+// with frame length 128 and {"purpose": "known-answer"} encryption context.
+// The data key is wrapped with the newTestCMM static key in the raw master key
+// format of this SDK, which differs from the raw AES keyring format.
+var knownAnswerPlaintext = []byte(strings.Repeat("Known answer plaintext. ", 6))
+
+const (
+	knownAnswerV1AES128NoKDF = "AYAAFDcgeBqyvltGc8C31iWeMGIAGQABAAdwdXJwb3NlAAxrbm93bi1hbnN3ZXIAAQADcmF3AAdzdGF0aWMxACwohNhECi69tgIP" +
+		"3TwkxGN0l9R+ki+Xygx6An0O0t2gGqtf+fUuKP2CSiVWswIAAAAADAAAAIAAAAAAAAAAAAAAAABwLCNcOtioUcdVLrKhvFu4AAAA" +
+		"AQAAAAAAAAAAAAAAAWkkGotpZshb+KHEtAXa/PeryaTDqNs+9bm3zmADM3LlHc4p4yPiAXuyZGRG41hIb9uDzW56pP1cFrfu+iHc" +
+		"a+U4OCrN5NSdRLsTeEEag2VO1JuvpoPxNgfpIHkJwE24USOlbjurTXjrQ2xdOstD9JONroOiXLQuUq8oMHDN+3YrYeXGOljnn3Cj" +
+		"4df/V7tMw/////8AAAACAAAAAAAAAAAAAAACAAAAEJIK8lOubLiY+5bQvf8meYX1e4U+rAkFsrAe9NcIJj0R"
+	knownAnswerV1AES256NoKDF = "AYAAeNxdv+Pb+KWfESEavryWfswAGQABAAdwdXJwb3NlAAxrbm93bi1hbnN3ZXIAAQADcmF3AAdzdGF0aWMxADyL2QLWGND+aS26" +
+		"+vk4M0/C/qoYWLwxt224Sooqk2/3a5dJr1gXbH+weBVftN4yK9D7+QW7s4TQsSvPOTgCAAAAAAwAAACAAAAAAAAAAAAAAAAAjNBj" +
+		"iaWTpPLo2wQ4uTW5sQAAAAEAAAAAAAAAAAAAAAGa6ncSlBXHeoRm9UvtEfJK8qoAwTERyXTpZ/hYFdHSMNvEXMtYQTc0z/YnOT7V" +
+		"RI3uoSxVv3AzpNGBnCOaeEM0JTTQroMwhJX/LSgINcP5aXH2ImcQG5vEH6R9A63gvmU9epv0kT/jPrEMwBEsSIH2r6q/+XrA1v+u" +
+		"csTM++OnmhhuABqlRtEitU22f18pNzn/////AAAAAgAAAAAAAAAAAAAAAgAAABCTcWs9qDiLjbJTnTKboRRxfFbnDKsKRM/WZZnA" +
+		"3/RBdw=="
+	knownAnswerV1AES256HKDFSHA384P384 = "AYADePCwoXy/UHlQEChEKMXoPv0AdgACABVhd3MtY3J5cHRvLXB1YmxpYy1rZXkAREFzOUdvWXltdStFSEs2cmo4Tis4OTFnWGda" +
+		"YkwrMjc4bmVhWE43U1dwdVBSVFpQTFFqSXRSR3cxZ3ZIQ2FJWW52QT09AAdwdXJwb3NlAAxrbm93bi1hbnN3ZXIAAQADcmF3AAdz" +
+		"dGF0aWMxADx43ANz3s7LP66fGFbyTY8JJpu0V1NFItBx27YBhOmTvXdSM7ieqYG0zno0waC51Ez4N6wcxnYVI8OMxF4CAAAAAAwA" +
+		"AACAAAAAAAAAAAAAAAAA+Gz5VrVLvLlt4BguDnCOzgAAAAEAAAAAAAAAAAAAAAEzLj/QKJ3A4XWTM47B0+0nHxxd2T1Eo0I8oSUa" +
+		"tWtosuVrkOoNoru88HBRwo3KIGDjDB06Cs80GcV8H6gJroB9w4efELlqnfb0JecDtIoU4ad0KFoaO5GDVWs13ax6ihOD5BK16dzW" +
+		"kCkJJmpBehL7Adzpdsbk4eHB1ARikEog9gOw+T/hdpDFT/EwIZp9UCT/////AAAAAgAAAAAAAAAAAAAAAgAAABD/NbKH033c3WUY" +
+		"fAVxEr2gYVaIjl26y6FVqjQ1Eph7PQBnMGUCMDfeH25c79a4r3ah8jfQKfIGu5ZrncoiYcLFtn6kWUpcpL2UhQOpRH6UYXm0sPyY" +
+		"nAIxAIHO+w54DY7ddi0BCQUTVyHo+Nmn1GeYlJMGuYs7THGsfN5i/Qz6caogVYeQynTp2Q=="
+)
+
+// Non-framed messages are encrypted by this SDK, the AWS Encryption SDK for Go
+// cannot encrypt them but decrypts both to knownAnswerPlaintext.
+const (
+	knownAnswerNonFramedAES256NoKDF = "AYAAeKhgNNowyiwJR29TluKDi4wAGQABAAdwdXJwb3NlAAxrbm93bi1hbnN3ZXIAAQADcmF3AAdzdGF0aWMxADw4fo4KeNKbisoN" +
+		"TOl+uYlptjNoOcNAHPQjWwWw5RSUelCjm0hEa5vUTT6Fq9BPjaxjAjaHuKbwI0jaCeYBAAAAAAwAAAAAAAAAAAAAAAAAAAAAG1zS" +
+		"Orv47iGXpdONx1XUoQAAAAAAAAAAAAAAAQAAAAAAAACQ5REvTRCg3FUTSIydOKyH0tc+Ans3sjMgXnZPXad/GOFZUGNxLgzLBnTw" +
+		"jqJDvjX4nt7JweAL6gY8I+ODg6GHHmXesstg6JvxSfpd983wU1jQYdZViBFxCTsgNCcENtAZe0D28gkrQ5llJ/KWyODmN9dwhmhA" +
+		"6/TpG+ykKrV2sM/Huce0DOrYWi5b+bpfluxQGMfcMO/XngrVZY5Ps9HTLw=="
+	knownAnswerNonFramedAES256HKDFSHA384P384 = "AYADeLm3H869Td7CWb/mDGHGORMAdgACABVhd3MtY3J5cHRvLXB1YmxpYy1rZXkAREFwQWhLTEwyS0lpaWxka3dxd3pNSFBEM095" +
+		"RXhMeGFMb1N2Tlh2RndKREIyZVIzZjJZUTgrUG56SENjU3BNRGJzUT09AAdwdXJwb3NlAAxrbm93bi1hbnN3ZXIAAQADcmF3AAdz" +
+		"dGF0aWMxADy0uQiRYgXDUYZoR1TIdPVVCsDbOFdrnSU+md69gCkuVYt/iA3zLePCvs6XL66ugydN56JXEKQiqiRTqAIBAAAAAAwA" +
+		"AAAAAAAAAAAAAAAAAAAAWjQPTi67G3HsWNE003hcLgAAAAAAAAAAAAAAAQAAAAAAAACQs2wRS/f7bdZfqAR6uDgu/afVOtE+M+Q7" +
+		"PdC6U4WsdD4gMRGQH2eSzxuASJX7vELbu2JFX9lI+sr+EaLMQx60p7Re/cpingHtC+Jr9m8YXS4znqqTLC8/3muyF/mEmVubXluh" +
+		"JtW1Pk9bPqbf2VIHrzmvuyQaNBMdXcdkWm97W/gGKHXyrCTp00fvWuv8rowXJkWtTOfN92mM/4U0UvXuEwBnMGUCMG6dmo+nR7yp" +
+		"SeTTzSc6amwuIbP6eSegY3i5EBSIsvgMtJW8Day072P/EkRB8Z+r9wIxAMZTTTgNVvrtqNUAABo0UsVz0iLMKQerXIfyd8eesJUk" +
+		"ef7u4vWcirOJPOWn6hBvNg=="
+)
+
+// Empty message is encrypted by the AWS Encryption SDK for Go v0.4.0 with the
+// default algorithm suite and frame length, its data key is wrapped as above.
+const knownAnswerEmptyAES256HKDFSHA512CommitKeyP384 = "AgV4IHUTZSQ9oHnTe7ImPIGfjP04PmIvkTlxxsFinwP7p18AdgACABVhd3MtY3J5cHRvLXB1YmxpYy1rZXkAREEyMzhuSUs5UGUz" +
+	"eU55WHV6ZVZXR0FGVXpmSHo2dHlwdFk0dWVxTmVpUnRTRlFkN3pPTng5MWlZNUxVL3A2dlh4UT09AAdwdXJwb3NlAAxrbm93bi1h" +
+	"bnN3ZXIAAQADcmF3AAdzdGF0aWMxADyhPskW3qRmjZLV8EiltdRogGjggCDYvpGp2bw3ZWEBg2RGLHcbe/wL6SzikAciDieuLKHd" +
+	"p5kdMY1p/n4CAAAQAP/dq55oLvbeObEkmxTslN95fOm2P9/LXAPs6ZcP8+0/+nZh3P++P35NRWOlV8FNr/////8AAAABAAAAAAAA" +
+	"AAAAAAABAAAAAAQ4PDmW+K4nIHNp+CLKnRUAZzBlAjEA0cRue58bpiOC0vz9h7Gdw0a4o7ILNbXAi5t1ymiADRt5jKY6hkk7rQFr" +
+	"FKBN17V6AjAH51y3A9JhHB9e+AYQRHbsjM4r/cW9WAhbLv/cCtiMoCNy8HU979u/EQR+GFQWcqA="
+
+func decodeKnownAnswer(t *testing.T, message string) []byte {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(message)
+	require.NoError(t, err)
+	return b
+}
+
+func Test_Client_Decrypt_KnownAnswer_V1(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyRequireEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+
+	tests := []struct {
+		alg     *suite.AlgorithmSuite
+		message string
+	}{
+		{suite.AES_128_GCM_IV12_TAG16_NO_KDF, knownAnswerV1AES128NoKDF},
+		{suite.AES_256_GCM_IV12_TAG16_NO_KDF, knownAnswerV1AES256NoKDF},
+		{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, knownAnswerV1AES256HKDFSHA384P384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg.Name(), func(t *testing.T) {
+			ciphertext := decodeKnownAnswer(t, tt.message)
+
+			plaintext, header, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, knownAnswerPlaintext, plaintext)
+			assert.Equal(t, tt.alg, header.AlgorithmSuite)
+			assert.Len(t, header.MessageID, 16)
+			assert.Equal(t, 128, header.FrameLength)
+
+			// default commitment policy rejects non-committing messages
+			_, _, err = client.NewClient().Decrypt(context.Background(), ciphertext, cmm)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Client_Decrypt_KnownAnswer_NonFramed(t *testing.T) {
+	cmm := newTestCMM(t)
+
+	cfg, err := clientconfig.NewConfigWithOpts(
+		clientconfig.WithCommitmentPolicy(suite.CommitmentPolicyRequireEncryptAllowDecrypt),
+	)
+	require.NoError(t, err)
+	c := client.NewClientWithConfig(cfg)
+
+	tests := []struct {
+		alg     *suite.AlgorithmSuite
+		message string
+	}{
+		{suite.AES_256_GCM_IV12_TAG16_NO_KDF, knownAnswerNonFramedAES256NoKDF},
+		{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, knownAnswerNonFramedAES256HKDFSHA384P384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg.Name(), func(t *testing.T) {
+			ciphertext := decodeKnownAnswer(t, tt.message)
+
+			plaintext, header, err := c.Decrypt(context.Background(), ciphertext, cmm)
+			require.NoError(t, err)
+			assert.Equal(t, knownAnswerPlaintext, plaintext)
+			assert.Equal(t, tt.alg, header.AlgorithmSuite)
+			assert.Equal(t, suite.NonFramedContent, header.ContentType())
+			assert.Equal(t, 0, header.FrameLength)
+		})
+	}
+}
+
+func Test_Client_Decrypt_KnownAnswer_Empty(t *testing.T) {
+	ciphertext := decodeKnownAnswer(t, knownAnswerEmptyAES256HKDFSHA512CommitKeyP384)
+
+	plaintext, header, err := client.NewClient().Decrypt(context.Background(), ciphertext, newTestCMM(t))
+	require.NoError(t, err)
+	assert.Empty(t, plaintext)
+	assert.Equal(t, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, header.AlgorithmSuite)
+	assert.Equal(t, 4096, header.FrameLength)
+}
@@ -5,24 +5,45 @@ package client
 
 import (
 	"fmt"
+	"path"
 
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 )
 
 const (
-	DefaultFrameLength = int(4096) // default frame size for encryption
+	DefaultFrameLength = int(4096)       // default frame size for encryption
+	DefaultBufferLimit = int64(64 << 20) // default plaintext held in memory by streaming decryption of signed messages
 )
 
+// defaultAlgorithm returns the default algorithm suite for encryption
+// that is allowed by the commitment policy.
+func defaultAlgorithm(policy suite.CommitmentPolicy) *suite.AlgorithmSuite {
+	if policy == suite.CommitmentPolicyForbidEncryptAllowDecrypt {
+		return suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384
+	}
+	return suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
+}
+
 // EncryptOptions defines the configuration options for the encryption process.
 // It contains settings such as the algorithm to use for encryption and the frame length.
 //
 // Fields:
 //   - Algorithm [suite.AlgorithmSuite]: AlgorithmSuite that defines the encryption algorithm to be used.
-//     If nil, a default [suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384] algorithm is used.
+//     If nil, a default [suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384] algorithm is used, or
+//     [suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384] with [suite.CommitmentPolicyForbidEncryptAllowDecrypt] policy.
 //   - FrameLength int: Specifies the frame length for encryption. If not set, a default value of DefaultFrameLength is used.
+//   - ContentType [suite.ContentType]: Specifies the message body content type. If not set,
+//     [suite.FramedContent] is used. FrameLength is ignored for [suite.NonFramedContent].
+//   - Concurrency int: Specifies the number of goroutines sealing frames. If not set, frames are sealed serially.
+//   - Progress [crypto.ProgressFunc]: Specifies a function called after each frame is encrypted.
+//     If not set, progress is not reported.
 type EncryptOptions struct {
 	Algorithm   *suite.AlgorithmSuite
 	FrameLength int
+	ContentType suite.ContentType
+	Concurrency int
+	Progress    crypto.ProgressFunc
 }
 
 // EncryptOptionFunc is a function type that applies a configuration option to an EncryptOptions struct.
@@ -31,7 +52,8 @@ type EncryptOptions struct {
 // Each function of this type takes a pointer to an EncryptOptions struct and modifies it accordingly.
 // It returns an error if the provided option is invalid or cannot be applied.
 //
-// Use WithAlgorithm and WithFrameLength to create EncryptOptionFunc functions.
+// Use WithAlgorithm, WithFrameLength, WithContentType, WithConcurrency and WithProgress
+// to create EncryptOptionFunc functions.
 type EncryptOptionFunc func(o *EncryptOptions) error
 
 // WithAlgorithm returns an EncryptOptionFunc that sets the encryption algorithm in EncryptOptions.
@@ -76,3 +98,397 @@ func WithFrameLength(frameLength int) EncryptOptionFunc {
 		return nil
 	}
 }
+
+// WithContentType returns an EncryptOptionFunc that sets the message body content type in EncryptOptions.
+// Use [suite.NonFramedContent] to produce single block non-framed messages for consumers
+// that require it. Non-framed content is limited to 64 GiB and buffered as a whole, prefer
+// framed content when possible.
+//
+// Parameters:
+//   - contentType [suite.ContentType]: [suite.FramedContent] or [suite.NonFramedContent].
+//
+// Returns:
+//   - EncryptOptionFunc: A function that sets the ContentType field in EncryptOptions.
+//
+// Errors:
+//   - If contentType is neither [suite.FramedContent] nor [suite.NonFramedContent],
+//     it returns an error indicating that the content type is not supported.
+func WithContentType(contentType suite.ContentType) EncryptOptionFunc {
+	return func(o *EncryptOptions) error {
+		if contentType != suite.FramedContent && contentType != suite.NonFramedContent {
+			return fmt.Errorf("content type %d not supported", contentType)
+		}
+		o.ContentType = contentType
+		return nil
+	}
+}
+
+// WithConcurrency returns an EncryptOptionFunc that sets the number of goroutines sealing frames in EncryptOptions.
+// Frames are still written and signed in sequence order, so the output is the same as with serial encryption.
+// Frames are sealed in parallel only when a single write contains more than one frame, it has no effect
+// on [suite.NonFramedContent].
+//
+// Parameters:
+//   - n int: The number of goroutines, 1 is serial encryption.
+//
+// Returns:
+//   - EncryptOptionFunc: A function that sets the Concurrency field in EncryptOptions.
+//
+// Errors:
+//   - If n is less than 1, it returns an error indicating that the concurrency is out of range.
+func WithConcurrency(n int) EncryptOptionFunc {
+	return func(o *EncryptOptions) error {
+		if n < 1 {
+			return fmt.Errorf("concurrency %d out of range, must be at least 1", n)
+		}
+		o.Concurrency = n
+		return nil
+	}
+}
+
+// WithProgress returns an EncryptOptionFunc that sets the progress function in EncryptOptions.
+// fn is called after each frame is encrypted with the frame sequence number, plaintext bytes
+// encrypted so far and the plaintext length, which is -1 for NewEncryptWriter.
+//
+// fn is called in frame sequence order from the goroutine calling Encrypt, Write or Close,
+// also with WithConcurrency. [suite.NonFramedContent] is reported once, as a single frame.
+//
+// Parameters:
+//   - fn [crypto.ProgressFunc]: The function receiving [crypto.Progress].
+//
+// Returns:
+//   - EncryptOptionFunc: A function that sets the Progress field in EncryptOptions.
+//
+// Errors:
+//   - If fn is nil, it returns an error.
+//
+// Example usage:
+//
+//	ciphertext, header, err := client.Encrypt(context.TODO(), plaintext, encryptionContext, materialsManager,
+//	    WithProgress(func(p crypto.Progress) {
+//	        fmt.Printf("%d of %d bytes\n", p.Bytes, p.Total)
+//	    }))
+func WithProgress(fn crypto.ProgressFunc) EncryptOptionFunc {
+	return func(o *EncryptOptions) error {
+		if fn == nil {
+			return fmt.Errorf("progress function must not be nil")
+		}
+		o.Progress = fn
+		return nil
+	}
+}
+
+// DecryptOptions defines the configuration options for the decryption process.
+//
+// Fields:
+//   - Concurrency int: Specifies the number of goroutines opening frames in Decrypt.
+//     If not set, frames are opened serially.
+//   - SignedMessagePolicy [crypto.SignedMessagePolicy]: Specifies when plaintext of signed messages is released.
+//     If not set, [crypto.SignedMessagePolicyReleaseAfterVerify] is used.
+//   - BufferLimit int64: Specifies the maximum plaintext held in memory until the signature is verified.
+//     If not set, a default value of DefaultBufferLimit is used.
+//   - SpillDir string: Specifies a directory for a temporary file which holds plaintext exceeding BufferLimit.
+//     If not set, plaintext exceeding BufferLimit fails decryption.
+//   - RequiredEncryptionContext [suite.EncryptionContext]: Specifies key-value pairs the message encryption
+//     context must contain. If not set, the encryption context is not checked.
+//   - AllowedAlgorithms []uint16: Specifies algorithm suite IDs a message can be decrypted with.
+//     If not set, any algorithm suite allowed by the commitment policy is accepted.
+//   - UnsignedOnly bool: Specifies whether messages encrypted with a signing algorithm suite are rejected.
+//     If not set, signed messages are accepted.
+//   - AllowedProviders []string: Specifies provider IDs of encrypted data keys passed to the materials manager.
+//     If not set, encrypted data keys of any provider are passed.
+//   - AllowedKeyIDs []string: Specifies [path.Match] patterns of key IDs, such as KMS key ARNs, of encrypted data
+//     keys passed to the materials manager. If not set, encrypted data keys with any key ID are passed.
+//   - RejectTrailingBytes bool: Specifies whether Decrypt fails if ciphertext has bytes after the message.
+//     If not set, trailing bytes are ignored.
+//   - Progress [crypto.ProgressFunc]: Specifies a function called after each frame is decrypted.
+//     If not set, progress is not reported.
+type DecryptOptions struct {
+	Concurrency               int
+	SignedMessagePolicy       crypto.SignedMessagePolicy
+	BufferLimit               int64
+	SpillDir                  string
+	RequiredEncryptionContext suite.EncryptionContext
+	AllowedAlgorithms         []uint16
+	UnsignedOnly              bool
+	AllowedProviders          []string
+	AllowedKeyIDs             []string
+	RejectTrailingBytes       bool
+	Progress                  crypto.ProgressFunc
+}
+
+// DecryptOptionFunc is a function type that applies a configuration option to a DecryptOptions struct.
+//
+// Use WithDecryptConcurrency, WithSignedMessagePolicy, WithBufferLimit, WithSpillDir,
+// WithRequiredEncryptionContext, WithAllowedAlgorithms, WithUnsignedOnly, WithAllowedProviders,
+// WithAllowedKeyIDs, WithRejectTrailingBytes and WithDecryptProgress to create DecryptOptionFunc functions.
+type DecryptOptionFunc func(o *DecryptOptions) error
+
+// WithDecryptConcurrency returns a DecryptOptionFunc that sets the number of goroutines opening frames
+// in DecryptOptions. The verifier is still updated in frame order, and the first frame failure stops
+// outstanding work. It has no effect on streaming decryption and [suite.NonFramedContent].
+//
+// Parameters:
+//   - n int: The number of goroutines, 1 is serial decryption.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the Concurrency field in DecryptOptions.
+//
+// Errors:
+//   - If n is less than 1, it returns an error indicating that the concurrency is out of range.
+func WithDecryptConcurrency(n int) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if n < 1 {
+			return fmt.Errorf("concurrency %d out of range, must be at least 1", n)
+		}
+		o.Concurrency = n
+		return nil
+	}
+}
+
+// WithSignedMessagePolicy returns a DecryptOptionFunc that sets the signed message policy in DecryptOptions.
+//
+// With [crypto.SignedMessagePolicyReleaseAfterVerify], plaintext of a signed message is not released
+// until the footer signature is verified. With [crypto.SignedMessagePolicyReleaseImmediately], each frame
+// is released as soon as it is decrypted, and a signature verification error is returned instead of io.EOF.
+// Plaintext already read must be discarded in that case.
+//
+// Parameters:
+//   - policy [crypto.SignedMessagePolicy]: The policy to be set.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the SignedMessagePolicy field in DecryptOptions.
+//
+// Errors:
+//   - If policy is not supported, it returns an error.
+func WithSignedMessagePolicy(policy crypto.SignedMessagePolicy) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if policy != crypto.SignedMessagePolicyReleaseAfterVerify && policy != crypto.SignedMessagePolicyReleaseImmediately {
+			return fmt.Errorf("signed message policy %d not supported", policy)
+		}
+		o.SignedMessagePolicy = policy
+		return nil
+	}
+}
+
+// WithBufferLimit returns a DecryptOptionFunc that sets the buffer limit in DecryptOptions.
+//
+// Parameters:
+//   - limit int64: The maximum plaintext in bytes held in memory until the signature is verified.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the BufferLimit field in DecryptOptions.
+//
+// Errors:
+//   - If limit is negative, it returns an error.
+func WithBufferLimit(limit int64) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if limit < 0 {
+			return fmt.Errorf("buffer limit must not be negative")
+		}
+		o.BufferLimit = limit
+		return nil
+	}
+}
+
+// WithSpillDir returns a DecryptOptionFunc that sets the spill directory in DecryptOptions.
+// Plaintext exceeding the buffer limit is written unencrypted into a temporary file in dir
+// until the signature is verified. The file is removed once plaintext is read or the reader is closed.
+//
+// Parameters:
+//   - dir string: The directory for temporary files, use os.TempDir for the default one.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the SpillDir field in DecryptOptions.
+//
+// Errors:
+//   - If dir is empty, it returns an error.
+func WithSpillDir(dir string) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if dir == "" {
+			return fmt.Errorf("spill dir must not be empty")
+		}
+		o.SpillDir = dir
+		return nil
+	}
+}
+
+// WithRequiredEncryptionContext returns a DecryptOptionFunc that sets the required encryption context
+// in DecryptOptions. It is also known as reproduced encryption context.
+//
+// Decryption fails right after the message header is read, before the materials manager is called
+// and any plaintext is returned, if a value of a required key differs from the message encryption
+// context. A required key missing from the message encryption context fails decryption before any
+// plaintext is returned, unless the materials manager reports it as required encryption context,
+// which is authenticated but not stored in the message, see materials.RequiredEncryptionContextCMM.
+// The message encryption context may contain additional pairs. The required encryption context is
+// passed to the materials manager as well.
+//
+// Parameters:
+//   - ec map[string]string: The required key-value pairs.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the RequiredEncryptionContext field in DecryptOptions.
+//
+// Errors:
+//   - If ec is empty, it returns an error.
+func WithRequiredEncryptionContext(ec map[string]string) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if len(ec) == 0 {
+			return fmt.Errorf("required encryption context must not be empty")
+		}
+		required := make(suite.EncryptionContext, len(ec))
+		for k, v := range ec {
+			required[k] = v
+		}
+		o.RequiredEncryptionContext = required
+		return nil
+	}
+}
+
+// WithAllowedAlgorithms returns a DecryptOptionFunc that sets the allowed algorithm suites in DecryptOptions.
+//
+// Decryption fails right after the message header is read, before the materials manager is called,
+// if the message is encrypted with an algorithm suite that is not in algorithms. The commitment policy
+// of the client is enforced in addition.
+//
+// Parameters:
+//   - algorithms [suite.AlgorithmSuite]: The algorithm suites to be allowed.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the AllowedAlgorithms field in DecryptOptions.
+//
+// Errors:
+//   - If algorithms is empty or contains nil, it returns an error.
+func WithAllowedAlgorithms(algorithms ...*suite.AlgorithmSuite) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if len(algorithms) == 0 {
+			return fmt.Errorf("allowed algorithms must not be empty")
+		}
+		ids := make([]uint16, 0, len(algorithms))
+		for _, alg := range algorithms {
+			if alg == nil {
+				return fmt.Errorf("allowed algorithm must not be nil")
+			}
+			ids = append(ids, alg.AlgorithmID)
+		}
+		o.AllowedAlgorithms = ids
+		return nil
+	}
+}
+
+// WithUnsignedOnly returns a DecryptOptionFunc that rejects messages encrypted with a signing
+// algorithm suite. Use it when plaintext must be streamed and released as soon as it is decrypted.
+//
+// Decryption fails right after the message header is read, before the materials manager is called,
+// if the message is signed.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the UnsignedOnly field in DecryptOptions.
+func WithUnsignedOnly() DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		o.UnsignedOnly = true
+		return nil
+	}
+}
+
+// WithAllowedProviders returns a DecryptOptionFunc that sets the allowed key providers in DecryptOptions.
+//
+// Encrypted data keys of other providers are removed from the message encrypted data keys before
+// they are passed to the materials manager, so that no key provider is called with them.
+// Decryption fails if no encrypted data key is left.
+//
+// Parameters:
+//   - providerIDs string: The provider IDs to be allowed, e.g. "aws-kms".
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the AllowedProviders field in DecryptOptions.
+//
+// Errors:
+//   - If providerIDs is empty or contains an empty provider ID, it returns an error.
+func WithAllowedProviders(providerIDs ...string) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if len(providerIDs) == 0 {
+			return fmt.Errorf("allowed providers must not be empty")
+		}
+		for _, id := range providerIDs {
+			if id == "" {
+				return fmt.Errorf("allowed provider ID must not be empty")
+			}
+		}
+		o.AllowedProviders = append([]string(nil), providerIDs...)
+		return nil
+	}
+}
+
+// WithAllowedKeyIDs returns a DecryptOptionFunc that sets the allowed key ID patterns in DecryptOptions.
+//
+// Encrypted data keys with key IDs not matching any of patterns are removed from the message encrypted
+// data keys before they are passed to the materials manager, so that KMS is never called with arbitrary
+// key ARNs of a malicious message. Decryption fails if no encrypted data key is left.
+//
+// Patterns use [path.Match] syntax, '*' does not match '/'.
+//
+// Parameters:
+//   - patterns string: The key ID patterns to be allowed, e.g. "arn:aws:kms:*:123456789012:key/*".
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the AllowedKeyIDs field in DecryptOptions.
+//
+// Errors:
+//   - If patterns is empty or contains a malformed pattern, it returns an error.
+func WithAllowedKeyIDs(patterns ...string) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if len(patterns) == 0 {
+			return fmt.Errorf("allowed key IDs must not be empty")
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("allowed key ID pattern %q is invalid", pattern)
+			}
+		}
+		o.AllowedKeyIDs = append([]string(nil), patterns...)
+		return nil
+	}
+}
+
+// WithRejectTrailingBytes returns a DecryptOptionFunc that makes Decrypt fail if ciphertext has
+// any bytes after the end of the message, instead of ignoring them. Use it to detect
+// concatenated messages, which can be decrypted with NewMessageIterator.
+//
+// It has no effect on streaming decryption, which never reads beyond the end of the message.
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the RejectTrailingBytes field in DecryptOptions.
+func WithRejectTrailingBytes() DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		o.RejectTrailingBytes = true
+		return nil
+	}
+}
+
+// WithDecryptProgress returns a DecryptOptionFunc that sets the progress function in DecryptOptions.
+// fn is called after each frame is decrypted with the frame sequence number, plaintext bytes
+// decrypted so far and the plaintext length, which is -1 for NewDecryptReader and NewMessageIterator.
+//
+// fn is called in frame sequence order from the goroutine calling Decrypt, Read or Next,
+// also with WithDecryptConcurrency. [suite.NonFramedContent] is reported once, as a single frame.
+// Progress is not reported by a RangeDecrypter.
+//
+// Parameters:
+//   - fn [crypto.ProgressFunc]: The function receiving [crypto.Progress].
+//
+// Returns:
+//   - DecryptOptionFunc: A function that sets the Progress field in DecryptOptions.
+//
+// Errors:
+//   - If fn is nil, it returns an error.
+func WithDecryptProgress(fn crypto.ProgressFunc) DecryptOptionFunc {
+	return func(o *DecryptOptions) error {
+		if fn == nil {
+			return fmt.Errorf("progress function must not be nil")
+		}
+		o.Progress = fn
+		return nil
+	}
+}
@@ -13,6 +13,7 @@ import (
 type ConfigOptions struct {
 	CommitmentPolicy     suite.CommitmentPolicy
 	MaxEncryptedDataKeys int
+	MaxDecryptAttempts   int
 }
 
 type ConfigOptionFunc func(o *ConfigOptions) error
@@ -20,6 +21,7 @@ type ConfigOptionFunc func(o *ConfigOptions) error
 type ClientConfig struct {
 	commitmentPolicy     suite.CommitmentPolicy
 	maxEncryptedDataKeys int
+	maxDecryptAttempts   int
 }
 
 func (c ClientConfig) CommitmentPolicy() suite.CommitmentPolicy {
@@ -30,6 +32,12 @@ func (c ClientConfig) MaxEncryptedDataKeys() int {
 	return c.maxEncryptedDataKeys
 }
 
+// MaxDecryptAttempts returns the maximum number of encrypted data key decryption
+// attempts per message across all key providers, 0 is unlimited.
+func (c ClientConfig) MaxDecryptAttempts() int {
+	return c.maxDecryptAttempts
+}
+
 func NewConfig() (*ClientConfig, error) {
 	return NewConfigWithOpts()
 }
@@ -38,6 +46,7 @@ func NewConfigWithOpts(optFns ...ConfigOptionFunc) (*ClientConfig, error) {
 	opts := ConfigOptions{
 		CommitmentPolicy:     defaultCommitment,
 		MaxEncryptedDataKeys: defaultMaxEDK,
+		MaxDecryptAttempts:   defaultMaxDecryptAttempts,
 	}
 	for _, optFn := range optFns {
 		if err := optFn(&opts); err != nil {
@@ -47,6 +56,7 @@ func NewConfigWithOpts(optFns ...ConfigOptionFunc) (*ClientConfig, error) {
 	return &ClientConfig{
 		commitmentPolicy:     opts.CommitmentPolicy,
 		maxEncryptedDataKeys: opts.MaxEncryptedDataKeys,
+		maxDecryptAttempts:   opts.MaxDecryptAttempts,
 	}, nil
 }
 
@@ -69,3 +79,17 @@ func WithMaxEncryptedDataKeys(maxEncryptedDataKeys int) ConfigOptionFunc {
 		return nil
 	}
 }
+
+// WithMaxDecryptAttempts limits the number of encrypted data key decryption attempts
+// per message, counted across all key providers of the default materials manager.
+// Attempts beyond the limit are skipped, and decryption fails with an error listing
+// skipped encrypted data keys if none of the attempted keys is decrypted.
+func WithMaxDecryptAttempts(maxDecryptAttempts int) ConfigOptionFunc {
+	return func(o *ConfigOptions) error {
+		if maxDecryptAttempts < 1 {
+			return fmt.Errorf("maxDecryptAttempts must be at least 1")
+		}
+		o.MaxDecryptAttempts = maxDecryptAttempts
+		return nil
+	}
+}
@@ -55,3 +55,13 @@ func TestNewConfigWithOptsFaulty(t *testing.T) {
 	assert.Error(t, err3)
 	assert.Nil(t, cfg3)
 }
+
+func TestNewConfigWithOpts_MaxDecryptAttempts(t *testing.T) {
+	cfg, err := NewConfigWithOpts(WithMaxDecryptAttempts(3))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.MaxDecryptAttempts())
+
+	cfg, err = NewConfigWithOpts(WithMaxDecryptAttempts(0))
+	assert.Error(t, err)
+	assert.Nil(t, cfg)
+}
@@ -8,4 +8,6 @@ import "github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 const (
 	defaultCommitment = suite.CommitmentPolicyRequireEncryptRequireDecrypt
 	defaultMaxEDK     = 10
+
+	defaultMaxDecryptAttempts = 0 // unlimited
 )
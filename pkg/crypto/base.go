@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"unsafe"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto/signature"
@@ -21,10 +23,20 @@ var (
 	ErrInvalidMessage = errors.New("invalid message format")
 	ErrDecryption     = errors.New("decryption error")
 	ErrEncryption     = errors.New("encryption error")
+
+	errWriterClosed = errors.New("writer already closed")
+	errReaderClosed = errors.New("reader already closed")
+
+	errEncryptionContextMismatch = errors.New("encryption context mismatch")
+	errAlgorithmNotAllowed       = errors.New("algorithm suite not allowed")
+	errNoAllowedEncryptedDataKey = errors.New("no allowed encrypted data key")
 )
 
 const (
-	firstByteEncryptedMessage = byte(0x02)
+	firstByteEncryptedMessage   = byte(0x02)
+	firstByteEncryptedMessageV1 = byte(0x01)
+	nonFramedSequenceNumber     = int(1)  // sequence number used for IV and body AAD of non-framed content
+	framesPerWorker             = int(16) // framesPerWorker is the number of frames each goroutine seals in a batch
 )
 
 type SdkDecrypter interface {
@@ -32,20 +44,78 @@ type SdkDecrypter interface {
 }
 
 type decrypter struct {
-	cmm             model.CryptoMaterialsManager
-	config          clientconfig.ClientConfig
-	aeadDecrypter   encryption.AEADDecrypter
-	header          *serialization.MessageHeader
-	verifier        signature.Verifier
-	_derivedDataKey []byte
+	ctx              context.Context // ctx is checked between frames
+	progress         progressTracker
+	cmm              model.CryptoMaterialsManager
+	config           clientconfig.ClientConfig
+	aeadDecrypter    encryption.AEADDecrypter
+	header           *serialization.MessageHeader
+	verifier         signature.Verifier
+	_derivedDataKey  []byte
+	readFrame        func(r io.Reader) (encryptedFrame, error)
+	concurrency      int
+	requiredEC       suite.EncryptionContext
+	allowedAlgs      []uint16
+	unsignedOnly     bool
+	allowedProviders []string
+	allowedKeyIDs    []string
+	rejectTrailing   bool
 }
 
-func Decrypt(ctx context.Context, config clientconfig.ClientConfig, ciphertext []byte, cmm model.CryptoMaterialsManager) ([]byte, *serialization.MessageHeader, error) {
-	dec := decrypter{
-		cmm:           cmm.GetInstance(),
-		config:        config,
-		aeadDecrypter: encryption.Gcm{},
+// SignedMessagePolicy defines when streaming decryption releases plaintext
+// of messages encrypted with a signing algorithm suite.
+type SignedMessagePolicy int8
+
+const (
+	// SignedMessagePolicyReleaseAfterVerify holds plaintext until the footer
+	// signature is verified, default.
+	SignedMessagePolicyReleaseAfterVerify SignedMessagePolicy = iota
+	// SignedMessagePolicyReleaseImmediately releases each frame as soon as it is
+	// decrypted, signature verification error is returned at the end of the message.
+	SignedMessagePolicyReleaseImmediately
+)
+
+// DecryptParams defines how DecryptWithParams opens frames and how NewDecryptReader releases plaintext.
+type DecryptParams struct {
+	Concurrency         int // Concurrency is the number of goroutines opening frames in DecryptWithParams, 1 or less is serial
+	SignedMessagePolicy SignedMessagePolicy
+	BufferLimit         int64  // BufferLimit is a maximum plaintext held in memory until the signature is verified
+	SpillDir            string // SpillDir is a directory for a temporary file with plaintext exceeding BufferLimit, empty disables spilling
+	// RequiredEncryptionContext are key-value pairs the message encryption context must contain
+	RequiredEncryptionContext suite.EncryptionContext
+	AllowedAlgorithms         []uint16     // AllowedAlgorithms are algorithm suite IDs a message can be decrypted with, empty allows any
+	UnsignedOnly              bool         // UnsignedOnly rejects messages encrypted with a signing algorithm suite
+	AllowedProviders          []string     // AllowedProviders are provider IDs of encrypted data keys passed to the CMM, empty allows any
+	AllowedKeyIDs             []string     // AllowedKeyIDs are path.Match patterns of key IDs passed to the CMM, empty allows any
+	RejectTrailingBytes       bool         // RejectTrailingBytes fails Decrypt if ciphertext has bytes after the message
+	Progress                  ProgressFunc // Progress is called after each frame, nil disables reporting. RangeDecrypter never reports.
+}
+
+func newDecrypter(config clientconfig.ClientConfig, cmm model.CryptoMaterialsManager, params DecryptParams) *decrypter {
+	return &decrypter{
+		ctx:              context.Background(),
+		progress:         newProgressTracker(params.Progress),
+		cmm:              cmm.GetInstance(),
+		config:           config,
+		aeadDecrypter:    encryption.Gcm{},
+		concurrency:      params.Concurrency,
+		requiredEC:       params.RequiredEncryptionContext,
+		allowedAlgs:      params.AllowedAlgorithms,
+		unsignedOnly:     params.UnsignedOnly,
+		allowedProviders: params.AllowedProviders,
+		allowedKeyIDs:    params.AllowedKeyIDs,
+		rejectTrailing:   params.RejectTrailingBytes,
 	}
+}
+
+// Decrypt decrypts ciphertext, frames are opened serially.
+func Decrypt(ctx context.Context, config clientconfig.ClientConfig, ciphertext []byte, cmm model.CryptoMaterialsManager) ([]byte, *serialization.MessageHeader, error) {
+	return DecryptWithParams(ctx, config, ciphertext, cmm, DecryptParams{})
+}
+
+// DecryptWithParams decrypts ciphertext, frames are opened as defined by params.
+func DecryptWithParams(ctx context.Context, config clientconfig.ClientConfig, ciphertext []byte, cmm model.CryptoMaterialsManager, params DecryptParams) ([]byte, *serialization.MessageHeader, error) {
+	dec := newDecrypter(config, cmm, params)
 
 	b, header, err := dec.decrypt(ctx, ciphertext)
 	if err != nil {
@@ -54,39 +124,390 @@ func Decrypt(ctx context.Context, config clientconfig.ClientConfig, ciphertext [
 	return b, header, nil
 }
 
+// DecryptTo decrypts ciphertext and appends plaintext to dst, returning the
+// extended slice. Plaintext is decrypted from ciphertext directly into dst,
+// dst is reallocated only if its capacity is not enough, or if its spare
+// capacity overlaps ciphertext.
+func DecryptTo(ctx context.Context, config clientconfig.ClientConfig, dst, ciphertext []byte, cmm model.CryptoMaterialsManager, params DecryptParams) ([]byte, *serialization.MessageHeader, error) {
+	dec := newDecrypter(config, cmm, params)
+
+	b, header, err := dec.decryptTo(ctx, dst, ciphertext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, err))
+	}
+	return b, header, nil
+}
+
+// NewDecryptReader reads and processes the message header from src. It returns
+// an [io.ReadCloser] which reads the message body from src and yields plaintext
+// frame by frame.
+//
+// For signing algorithm suites, params.SignedMessagePolicy defines whether
+// plaintext is released before the footer signature is verified.
+//
+// Close releases held plaintext and removes a temporary file if any, it does not close src.
+func NewDecryptReader(ctx context.Context, config clientconfig.ClientConfig, src io.Reader, cmm model.CryptoMaterialsManager, params DecryptParams) (io.ReadCloser, *serialization.MessageHeader, error) {
+	if src == nil {
+		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, fmt.Errorf("source must not be nil")))
+	}
+	dec := newDecrypter(config, cmm, params)
+	if err := dec.start(ctx, src); err != nil {
+		dec.destroy()
+		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, err))
+	}
+	r := &decryptReader{dec: dec, src: src}
+	if dec.verifier != nil && params.SignedMessagePolicy == SignedMessagePolicyReleaseAfterVerify {
+		r.held = &spool{limit: params.BufferLimit, dir: params.SpillDir}
+	}
+	return r, dec.header, nil
+}
+
+// decryptReader is an [io.ReadCloser] on top of decrypter. Any error is
+// sticky, plaintext already read must be discarded if Read returns an error
+// other than [io.EOF].
+type decryptReader struct {
+	dec    *decrypter
+	src    io.Reader
+	held   *spool    // held is plaintext waiting for signature verification, nil if released immediately
+	out    io.Reader // out is plaintext ready to be read
+	done   bool
+	err    error
+	closed bool
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, errReaderClosed))
+	}
+	for {
+		if r.out != nil {
+			n, err := r.out.Read(p)
+			if errors.Is(err, io.EOF) {
+				r.out = nil
+			} else if err != nil {
+				r.fail(fmt.Errorf("plaintext read error: %w", err))
+				return 0, r.err
+			}
+			if n > 0 || len(p) == 0 {
+				return n, nil
+			}
+			continue
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			r.release()
+			return 0, io.EOF
+		}
+		r.advance()
+	}
+}
+
+// advance decrypts the next frame, or the whole non-framed body, and
+// verifies the footer after the final frame.
+func (r *decryptReader) advance() {
+	plaintext, final, err := r.dec.next(r.src)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	if r.held != nil {
+		if _, err := r.held.Write(plaintext); err != nil {
+			r.fail(err)
+			return
+		}
+	} else {
+		r.out = bytes.NewReader(plaintext)
+	}
+	if !final {
+		return
+	}
+	if err := r.dec.finish(r.src); err != nil {
+		// released plaintext is still readable, the error is returned instead of io.EOF
+		r.fail(err)
+		return
+	}
+	r.dec.destroy()
+	r.done = true
+	if r.held != nil {
+		out, err := r.held.reader()
+		if err != nil {
+			r.fail(err)
+			return
+		}
+		r.out = out
+	}
+}
+
+func (r *decryptReader) fail(err error) {
+	r.dec.destroy()
+	r.err = fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, err))
+	if r.held != nil {
+		r.release()
+		r.out = nil
+	}
+}
+
+// release discards held plaintext.
+func (r *decryptReader) release() {
+	if r.held != nil {
+		_ = r.held.Close()
+		r.held = nil
+	}
+}
+
+func (r *decryptReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.out = nil
+	r.dec.destroy()
+	if r.held != nil {
+		err := r.held.Close()
+		r.held = nil
+		if err != nil {
+			return fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, err))
+		}
+	}
+	return nil
+}
+
 var _ SdkDecrypter = (*decrypter)(nil)
 
+// checkContext returns an error wrapping ctx.Err() if ctx is done, it does not block.
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("operation stopped: %w", ctx.Err())
+	default:
+		return nil
+	}
+}
+
+// splitEncryptionContext splits ec into encryption context stored in the message
+// header and encryption context of requiredKeys, which is only authenticated.
+func splitEncryptionContext(ec suite.EncryptionContext, requiredKeys []string) (stored, authOnly suite.EncryptionContext, err error) {
+	if len(requiredKeys) == 0 {
+		return ec, nil, nil
+	}
+	stored = make(suite.EncryptionContext, len(ec))
+	for k, v := range ec {
+		stored[k] = v
+	}
+	authOnly = make(suite.EncryptionContext, len(requiredKeys))
+	for _, k := range requiredKeys {
+		v, ok := ec[k]
+		if !ok {
+			return nil, nil, fmt.Errorf("required key %q is missing from materials: %w", k, errEncryptionContextMismatch)
+		}
+		authOnly[k] = v
+		delete(stored, k)
+	}
+	return stored, authOnly, nil
+}
+
+// headerAuthentication is a deserialized message header authentication.
+type headerAuthentication interface {
+	IV() []byte
+	AuthData() []byte
+	Serialize() []byte
+}
+
+// encryptedContent is a deserialized non-framed message body or a single frame.
+type encryptedContent interface {
+	IV() []byte
+	EncryptedContent() []byte
+	AuthenticationTag() []byte
+	Bytes() []byte
+}
+
+// encryptedFrame is a deserialized message body frame.
+type encryptedFrame interface {
+	encryptedContent
+	IsFinal() bool
+	SequenceNumber() int
+}
+
 type SdkEncrypter interface {
 	encrypt(ctx context.Context, source []byte, ec suite.EncryptionContext) ([]byte, *serialization.MessageHeader, error)
 }
 
+// frameBody accumulates encrypted frames until they are flushed into output.
+type frameBody interface {
+	AddFrame(final bool, seqNum int, IV []byte, contentLength int, ciphertext, authTag []byte) error
+	Flush() []byte
+}
+
 type encrypter struct {
+	ctx             context.Context // ctx is checked between frames
+	progress        progressTracker
 	cmm             model.CryptoMaterialsManager
 	config          clientconfig.ClientConfig
 	algorithm       *suite.AlgorithmSuite
 	frameLength     int
+	contentType     suite.ContentType
 	aeadEncrypter   encryption.AEADEncrypter
 	header          *serialization.MessageHeader
+	authOnlyEC      suite.EncryptionContext // authOnlyEC is authenticated by header auth but not stored in header
 	_derivedDataKey []byte
+	materials       model.EncryptionMaterial
 	signer          signature.Signer
-	ciphertextBuf   *bytes.Buffer
+	output          io.Writer
+	dst             *appendBuffer // dst is output when it is appendBuffer, nil otherwise
+	body            frameBody
+	seqNum          int
+	plaintextBuf    []byte
+	concurrency     int
 }
 
-func Encrypt(ctx context.Context, config clientconfig.ClientConfig, source []byte, ec suite.EncryptionContext, cmm model.CryptoMaterialsManager, algorithm *suite.AlgorithmSuite, frameLength int) ([]byte, *serialization.MessageHeader, error) {
-	enc := encrypter{
+// EncryptParams defines the layout of the message produced by EncryptWithParams.
+//
+// FrameLength is ignored for [suite.NonFramedContent] content type.
+type EncryptParams struct {
+	Algorithm   *suite.AlgorithmSuite
+	FrameLength int
+	ContentType suite.ContentType
+	Concurrency int          // Concurrency is the number of goroutines sealing frames, 1 or less is serial
+	Progress    ProgressFunc // Progress is called after each frame, nil disables reporting
+}
+
+func newEncrypter(config clientconfig.ClientConfig, cmm model.CryptoMaterialsManager, params EncryptParams) *encrypter {
+	frameLength := params.FrameLength
+	if params.ContentType == suite.NonFramedContent {
+		frameLength = 0
+	}
+	return &encrypter{
+		ctx:           context.Background(),
+		progress:      newProgressTracker(params.Progress),
 		cmm:           cmm.GetInstance(),
 		config:        config,
-		algorithm:     algorithm,
+		algorithm:     params.Algorithm,
 		frameLength:   frameLength,
+		contentType:   params.ContentType,
 		aeadEncrypter: encryption.Gcm{},
-		ciphertextBuf: new(bytes.Buffer),
+		concurrency:   params.Concurrency,
 	}
+}
+
+// Encrypt encrypts source into a framed message with the given algorithm and frame length.
+func Encrypt(ctx context.Context, config clientconfig.ClientConfig, source []byte, ec suite.EncryptionContext, cmm model.CryptoMaterialsManager, algorithm *suite.AlgorithmSuite, frameLength int) ([]byte, *serialization.MessageHeader, error) {
+	return EncryptWithParams(ctx, config, source, ec, cmm, EncryptParams{
+		Algorithm:   algorithm,
+		FrameLength: frameLength,
+		ContentType: suite.FramedContent,
+	})
+}
+
+// EncryptWithParams encrypts source into a message with the layout defined by params.
+func EncryptWithParams(ctx context.Context, config clientconfig.ClientConfig, source []byte, ec suite.EncryptionContext, cmm model.CryptoMaterialsManager, params EncryptParams) ([]byte, *serialization.MessageHeader, error) {
+	enc := newEncrypter(config, cmm, params)
 	ciphertext, header, err := enc.encrypt(ctx, source, ec)
 	if err != nil {
-		// TODO andrew clean up derived data key
 		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, err))
 	}
 	return ciphertext, header, nil
 }
 
+// EncryptTo encrypts source and appends the message to dst, returning the
+// extended slice. Frames are sealed from source directly into dst, dst is
+// reallocated only if its capacity is not enough, or if its spare capacity
+// overlaps source.
+func EncryptTo(ctx context.Context, config clientconfig.ClientConfig, dst, source []byte, ec suite.EncryptionContext, cmm model.CryptoMaterialsManager, params EncryptParams) ([]byte, *serialization.MessageHeader, error) {
+	enc := newEncrypter(config, cmm, params)
+	ciphertext, header, err := enc.encryptTo(ctx, dst, source, ec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, err))
+	}
+	return ciphertext, header, nil
+}
+
+// NewEncryptWriter obtains encryption materials and writes the message header
+// into dst. It returns an [io.WriteCloser] which encrypts plaintext written to it
+// frame by frame into dst.
+//
+// Close must be called to write the final frame and the footer, the message
+// is incomplete otherwise. Close does not close dst.
+func NewEncryptWriter(ctx context.Context, config clientconfig.ClientConfig, dst io.Writer, ec suite.EncryptionContext, cmm model.CryptoMaterialsManager, params EncryptParams) (io.WriteCloser, *serialization.MessageHeader, error) {
+	enc := newEncrypter(config, cmm, params)
+	if err := enc.start(ctx, dst, ec, -1); err != nil {
+		enc.destroy()
+		return nil, nil, fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, err))
+	}
+	return &encryptWriter{enc: enc}, enc.header, nil
+}
+
+// encryptWriter is an [io.WriteCloser] on top of encrypter. Any error is
+// sticky, the message is unusable once Write or Close failed.
+type encryptWriter struct {
+	enc    *encrypter
+	err    error
+	closed bool
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, errWriterClosed))
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.enc.write(p); err != nil {
+		w.enc.destroy()
+		w.err = fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, err))
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, errWriterClosed))
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	defer w.enc.destroy()
+	if err := w.enc.close(); err != nil {
+		w.err = fmt.Errorf("SDK error: %w", errors.Join(ErrEncryption, err))
+		return w.err
+	}
+	return nil
+}
+
 var _ SdkEncrypter = (*encrypter)(nil)
+
+// spareFor returns dst limited to its length if the spare capacity of dst
+// overlaps src, so that appending to it allocates a new buffer instead of
+// overwriting src while it is still being read.
+func spareFor(dst, src []byte) []byte {
+	if anyOverlap(dst[len(dst):cap(dst)], src) {
+		return dst[:len(dst):len(dst)]
+	}
+	return dst
+}
+
+// anyOverlap reports whether x and y share any memory.
+func anyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// destroyMaterials wipes key material of m if it implements [model.Destroyer].
+func destroyMaterials(m any) {
+	if d, ok := m.(model.Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+// requiredEncryptionContextKeys returns required encryption context keys of m,
+// nil if m does not implement [model.RequiredEncryptionContextMaterial].
+func requiredEncryptionContextKeys(m any) []string {
+	if r, ok := m.(model.RequiredEncryptionContextMaterial); ok {
+		return r.RequiredEncryptionContextKeys()
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"crypto/hmac"
 	"fmt"
+	"io"
+	"path"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto/signature"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/helpers/bodyaad"
@@ -16,58 +18,177 @@ import (
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/serialization"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/keyderivation"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 // decrypt ciphertext decryption
 func (d *decrypter) decrypt(ctx context.Context, ciphertext []byte) ([]byte, *serialization.MessageHeader, error) {
-	var b []byte
-	b = make([]byte, len(ciphertext))
-	copy(b, ciphertext)
-	if len(ciphertext) == 0 || len(b) == 0 {
+	plaintext, header, err := d.decryptTo(ctx, nil, ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+	// empty plaintext is an empty slice, not nil
+	if plaintext == nil {
+		plaintext = []byte{}
+	}
+	return plaintext, header, nil
+}
+
+// decryptTo decrypts ciphertext and appends plaintext to dst. Frames are opened
+// from ciphertext directly into dst, ciphertext is never modified.
+//
+// Header fields are copied while reading, returned header does not share
+// memory with ciphertext.
+func (d *decrypter) decryptTo(ctx context.Context, dst, ciphertext []byte) ([]byte, *serialization.MessageHeader, error) {
+	defer d.destroy()
+	d.ctx = ctx
+
+	if len(ciphertext) == 0 {
 		return nil, nil, fmt.Errorf("empty ciphertext")
 	}
 
 	// early stage check if cipher text contains needed first byte of message version
 	// by doing this we avoid mistakes with base64 byte sequence
-	if ciphertext[0] != firstByteEncryptedMessage {
+	if ciphertext[0] != firstByteEncryptedMessage && ciphertext[0] != firstByteEncryptedMessageV1 {
 		return nil, nil, fmt.Errorf("first byte does not contain message version: %w", ErrInvalidMessage)
 	}
-	buf := bytes.NewBuffer(b)
 
-	if err := d.decryptHeader(ctx, buf); err != nil {
+	dst = spareFor(dst, ciphertext)
+
+	r := bytes.NewReader(ciphertext)
+	header, headerAuth, err := serialization.ReadHeader(r, d.config.MaxEncryptedDataKeys())
+	if err != nil {
 		return nil, nil, err
 	}
+	if err := d.processHeader(ctx, header, headerAuth); err != nil {
+		return nil, nil, err
+	}
+	buf := bytes.NewBuffer(ciphertext[len(ciphertext)-r.Len():])
 
-	body, err := d.decryptBody(buf)
+	plaintext, err := d.decryptBody(dst, buf)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if err := d.finishBuffer(buf); err != nil {
+		// plaintext is appended into dst spare capacity, do not leave it there
+		zeroize.Bytes(plaintext[len(dst):])
+		return nil, nil, err
+	}
+
+	return plaintext, d.header, nil
+}
+
+// finishBuffer verifies the footer signature, if any, and checks trailing bytes
+// after the message body in buf.
+func (d *decrypter) finishBuffer(buf *bytes.Buffer) error {
 	if d.verifier != nil {
 		footer, errFooter := serialization.MessageFooter.FromBuffer(d.header.AlgorithmSuite, buf)
 		if errFooter != nil {
-			return nil, nil, errFooter
+			return errFooter
 		}
 
 		if errSig := d.verifier.Verify(footer.Signature); errSig != nil {
-			return nil, nil, errSig
+			return errSig
 		}
 	}
 	// TODO check if alg is non-signing, but footer has signature, return error
 
-	return body, d.header, nil
+	if d.rejectTrailing && buf.Len() > 0 {
+		return fmt.Errorf("%d trailing bytes after message: %w", buf.Len(), ErrInvalidMessage)
+	}
+	return nil
+}
+
+// start reads message header from src and processes it.
+func (d *decrypter) start(ctx context.Context, src io.Reader) error {
+	d.ctx = ctx
+	header, headerAuth, err := serialization.ReadHeader(src, d.config.MaxEncryptedDataKeys())
+	if err != nil {
+		return err
+	}
+
+	if err := d.processHeader(ctx, header, headerAuth); err != nil {
+		return err
+	}
+
+	if header.ContentType() == suite.FramedContent {
+		body, err := serialization.MessageBody.NewBody(header.AlgorithmSuite, header.FrameLength)
+		if err != nil {
+			return fmt.Errorf("body error: %w", err)
+		}
+		d.readFrame = func(r io.Reader) (encryptedFrame, error) {
+			return body.ReadFrame(r)
+		}
+	}
+
+	return nil
+}
+
+// next reads the next frame from src and decrypts it. Non-framed content
+// is read and decrypted as a whole. It reports whether the content is final.
+func (d *decrypter) next(src io.Reader) ([]byte, bool, error) {
+	if err := checkContext(d.ctx); err != nil {
+		return nil, false, err
+	}
+	if d.header.ContentType() == suite.NonFramedContent {
+		body, err := serialization.MessageBody.ReadNonFramedBody(src, d.header.AlgorithmSuite)
+		if err != nil {
+			return nil, false, fmt.Errorf("body error: %w", err)
+		}
+		plaintext, err := d.decryptNonFramedContent(nil, body)
+		if err != nil {
+			return nil, false, err
+		}
+		if d.verifier != nil {
+			if err := d.updateVerifier(body.Bytes()); err != nil {
+				return nil, false, err
+			}
+		}
+		d.progress.report(nonFramedSequenceNumber, len(plaintext))
+		return plaintext, true, nil
+	}
+
+	frame, err := d.readFrame(src)
+	if err != nil {
+		return nil, false, fmt.Errorf("body error: %w", err)
+	}
+	plaintext, err := d.decryptFrame(frame)
+	if err != nil {
+		return nil, false, err
+	}
+	d.progress.report(frame.SequenceNumber(), len(plaintext))
+	return plaintext, frame.IsFinal(), nil
 }
 
-func (d *decrypter) decryptHeader(ctx context.Context, buf *bytes.Buffer) error {
-	header, headerAuth, err := serialization.DeserializeHeader(buf, d.config.MaxEncryptedDataKeys())
+// finish reads the footer from src and verifies the signature if the algorithm is signing.
+func (d *decrypter) finish(src io.Reader) error {
+	if d.verifier == nil {
+		return nil
+	}
+	footer, err := serialization.MessageFooter.FromReader(d.header.AlgorithmSuite, src)
 	if err != nil {
 		return err
 	}
+	return d.verifier.Verify(footer.Signature)
+}
 
+// processHeader obtains decryption materials for the deserialized header,
+// derives data key and validates header authentication.
+func (d *decrypter) processHeader(ctx context.Context, header *serialization.MessageHeader, headerAuth headerAuthentication) error {
 	if errPolicy := policy.Commitment.ValidatePolicyOnDecrypt(d.config.CommitmentPolicy(), header.AlgorithmSuite); errPolicy != nil {
 		return errPolicy
 	}
 
+	if err := d.validateAlgorithm(header.AlgorithmSuite); err != nil {
+		return err
+	}
+
+	encryptionContext := header.AADData.AsEncryptionContext()
+	if err := d.validateEncryptionContext(encryptionContext); err != nil {
+		return err
+	}
+
 	if header.AlgorithmSuite.IsSigning() {
 		d.verifier = signature.NewECCVerifier(
 			header.AlgorithmSuite.Authentication.HashFunc,
@@ -81,16 +202,25 @@ func (d *decrypter) decryptHeader(ctx context.Context, buf *bytes.Buffer) error
 		}
 	}
 
+	edks, err := d.filterEncryptedDataKeys(serialization.EDK.AsKeys(header.EncryptedDataKeys))
+	if err != nil {
+		return err
+	}
+
 	dmr := model.DecryptionMaterialsRequest{
-		Algorithm:         header.AlgorithmSuite,
-		EncryptedDataKeys: serialization.EDK.AsKeys(header.EncryptedDataKeys),
-		EncryptionContext: header.AADData.AsEncryptionContext(),
+		Algorithm:                   header.AlgorithmSuite,
+		EncryptedDataKeys:           edks,
+		EncryptionContext:           encryptionContext,
+		ReproducedEncryptionContext: d.requiredEC,
+		MaxDecryptAttempts:          d.config.MaxDecryptAttempts(),
 	}
 
 	decMaterials, err := d.cmm.DecryptMaterials(ctx, dmr)
 	if err != nil {
 		return fmt.Errorf("decrypt materials: %w", err)
 	}
+	// data key is only needed to derive keys below
+	defer destroyMaterials(decMaterials)
 
 	if d.verifier != nil {
 		if errLK := d.verifier.LoadECCKey(decMaterials.VerificationKey()); errLK != nil {
@@ -98,10 +228,20 @@ func (d *decrypter) decryptHeader(ctx context.Context, buf *bytes.Buffer) error
 		}
 	}
 
+	authOnlyEC, err := d.requiredEncryptionContext(encryptionContext, requiredEncryptionContextKeys(decMaterials))
+	if err != nil {
+		return err
+	}
+
+	if d._derivedDataKey != nil {
+		return fmt.Errorf("decrypt derived data key already exists")
+	}
 	derivedDataKey, err := keyderivation.DeriveDataEncryptionKey(decMaterials.DataKey().DataKey(), header.AlgorithmSuite, header.MessageID)
 	if err != nil {
 		return fmt.Errorf("decrypt key derivation error: %w", err)
 	}
+	// set before validation, so that destroy wipes it on failure too
+	d._derivedDataKey = derivedDataKey
 
 	if header.AlgorithmSuite.IsCommitting() {
 		expectedCommitmentKey, err := keyderivation.CalculateCommitmentKey(decMaterials.DataKey().DataKey(), header.AlgorithmSuite, header.MessageID)
@@ -114,15 +254,10 @@ func (d *decrypter) decryptHeader(ctx context.Context, buf *bytes.Buffer) error
 		}
 	}
 
-	if errHeaderAuth := d.aeadDecrypter.ValidateHeaderAuth(derivedDataKey, headerAuth.AuthData(), header.Bytes()); errHeaderAuth != nil {
+	if errHeaderAuth := d.validateHeaderAuth(derivedDataKey, header, header.AuthenticationData(authOnlyEC), headerAuth.IV(), headerAuth.AuthData()); errHeaderAuth != nil {
 		return fmt.Errorf("decrypt header auth error: %w", errHeaderAuth)
 	}
 
-	if d._derivedDataKey != nil {
-		return fmt.Errorf("decrypt derived data key already exists")
-	}
-	d._derivedDataKey = derivedDataKey
-
 	if d.header != nil {
 		return fmt.Errorf("decrypt header already exists")
 	}
@@ -131,59 +266,271 @@ func (d *decrypter) decryptHeader(ctx context.Context, buf *bytes.Buffer) error
 	return nil
 }
 
-func (d *decrypter) decryptBody(buf *bytes.Buffer) ([]byte, error) {
+// validateAlgorithm checks that the message algorithm suite is allowed for decryption.
+func (d *decrypter) validateAlgorithm(alg *suite.AlgorithmSuite) error {
+	if d.unsignedOnly && alg.IsSigning() {
+		return fmt.Errorf("%v is signing, only unsigned messages allowed: %w", alg, errAlgorithmNotAllowed)
+	}
+	if len(d.allowedAlgs) == 0 {
+		return nil
+	}
+	for _, id := range d.allowedAlgs {
+		if id == alg.AlgorithmID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v: %w", alg, errAlgorithmNotAllowed)
+}
+
+// filterEncryptedDataKeys returns encrypted data keys of allowed providers with
+// allowed key IDs. It returns an error if none of the keys is allowed.
+func (d *decrypter) filterEncryptedDataKeys(edks []model.EncryptedDataKeyI) ([]model.EncryptedDataKeyI, error) {
+	if len(d.allowedProviders) == 0 && len(d.allowedKeyIDs) == 0 {
+		return edks, nil
+	}
+	allowed := make([]model.EncryptedDataKeyI, 0, len(edks))
+	for _, k := range edks {
+		if d.isProviderAllowed(k.KeyProvider().ProviderID) && d.isKeyIDAllowed(k.KeyID()) {
+			allowed = append(allowed, k)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("%d encrypted data keys filtered out: %w", len(edks), errNoAllowedEncryptedDataKey)
+	}
+	return allowed, nil
+}
+
+func (d *decrypter) isProviderAllowed(providerID string) bool {
+	if len(d.allowedProviders) == 0 {
+		return true
+	}
+	for _, p := range d.allowedProviders {
+		if p == providerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *decrypter) isKeyIDAllowed(keyID string) bool {
+	if len(d.allowedKeyIDs) == 0 {
+		return true
+	}
+	for _, pattern := range d.allowedKeyIDs {
+		// patterns are validated by options, bad pattern never matches
+		if ok, _ := path.Match(pattern, keyID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEncryptionContext checks that values of required keys in the message
+// encryption context match. Required keys missing from the message are checked
+// by requiredEncryptionContext once decryption materials are known.
+func (d *decrypter) validateEncryptionContext(ec suite.EncryptionContext) error {
+	for k, v := range d.requiredEC {
+		got, ok := ec[k]
+		if ok && got != v {
+			return fmt.Errorf("value of key %q differs from required: %w", k, errEncryptionContextMismatch)
+		}
+	}
+	return nil
+}
+
+// requiredEncryptionContext returns encryption context which is authenticated
+// but not stored in the message header. Materials requiredKeys must be supplied
+// by the caller, and any other required key must be in the message encryption context.
+func (d *decrypter) requiredEncryptionContext(ec suite.EncryptionContext, requiredKeys []string) (suite.EncryptionContext, error) {
+	authOnly := make(suite.EncryptionContext, len(requiredKeys))
+	for _, k := range requiredKeys {
+		v, ok := d.requiredEC[k]
+		if !ok {
+			return nil, fmt.Errorf("required key %q is not supplied: %w", k, errEncryptionContextMismatch)
+		}
+		if _, stored := ec[k]; !stored {
+			authOnly[k] = v
+		}
+	}
+	for k := range d.requiredEC {
+		if _, stored := ec[k]; !stored {
+			if _, ok := authOnly[k]; !ok {
+				return nil, fmt.Errorf("required key %q is missing: %w", k, errEncryptionContextMismatch)
+			}
+		}
+	}
+	return authOnly, nil
+}
+
+// validateHeaderAuth validates header authentication tag. Message format
+// version 1 carries header authentication IV, which is used as is.
+func (d *decrypter) validateHeaderAuth(derivedDataKey []byte, header *serialization.MessageHeader, authData, iv, authTag []byte) error {
+	if header.AlgorithmSuite.MessageFormatVersion == suite.MessageFormatVersion1 {
+		if _, err := d.aeadDecrypter.Decrypt(derivedDataKey, iv, []byte(nil), authTag, authData); err != nil {
+			return fmt.Errorf("invalid header auth: %w", err)
+		}
+		return nil
+	}
+	return d.aeadDecrypter.ValidateHeaderAuth(derivedDataKey, authTag, authData)
+}
+
+// decryptBody decrypts the message body from buf and appends plaintext to dst.
+// Verifier is updated with body bytes as they are in buf.
+func (d *decrypter) decryptBody(dst []byte, buf *bytes.Buffer) ([]byte, error) {
+	if err := checkContext(d.ctx); err != nil {
+		return nil, err
+	}
+	raw := buf.Bytes()
+	if d.header.ContentType() == suite.NonFramedContent {
+		body, err := serialization.DeserializeNonFramedBody(buf, d.header.AlgorithmSuite)
+		if err != nil {
+			return nil, fmt.Errorf("body error: %w", err)
+		}
+		if err := d.updateVerifierWithBody(raw[:len(raw)-buf.Len()]); err != nil {
+			return nil, err
+		}
+		n := len(dst)
+		plaintext, err := d.decryptNonFramedContent(dst, body)
+		if err != nil {
+			return nil, err
+		}
+		d.progress.total = int64(len(plaintext) - n)
+		d.progress.report(nonFramedSequenceNumber, len(plaintext)-n)
+		return plaintext, nil
+	}
+
 	body, err := serialization.DeserializeBody(buf, d.header.AlgorithmSuite, d.header.FrameLength)
 	if err != nil {
 		return nil, fmt.Errorf("body error: %w", err)
 	}
+	if err := d.updateVerifierWithBody(raw[:len(raw)-buf.Len()]); err != nil {
+		return nil, err
+	}
 
-	plaintext := new(bytes.Buffer)
-	readBytes := 0
-
+	frames := make([]encryptedFrame, 0, len(body.Frames()))
+	var total int64
 	for _, frame := range body.Frames() {
-		associatedData := bodyaad.BodyAAD.ContentAADBytes(
-			d.header.MessageID,
-			bodyaad.BodyAAD.ContentString(suite.FramedContent, frame.IsFinal()),
-			frame.SequenceNumber(),
-			len(frame.EncryptedContent()),
-		)
-		b, errAead := d.aeadDecrypter.Decrypt(
-			d._derivedDataKey,
-			frame.IV(),
-			frame.EncryptedContent(),
-			frame.AuthenticationTag(),
-			associatedData,
-		)
-		if errAead != nil {
-			return nil, fmt.Errorf("decrypt frame error: %w", errAead)
-		}
-		readBytes += len(b)
-		plaintext.Write(b)
-		// if alg is signing, write each frame bytes to verifier to update message hash
-		if d.verifier != nil {
-			if err := d.updateVerifier(frame.Bytes()); err != nil {
-				return nil, err
-			}
+		frames = append(frames, frame)
+		total += int64(len(frame.EncryptedContent()))
+	}
+	d.progress.total = total
+	return d.decryptFrames(dst, frames)
+}
+
+// updateVerifierWithBody updates verifier, if any, with serialized body bytes.
+func (d *decrypter) updateVerifierWithBody(b []byte) error {
+	if d.verifier == nil {
+		return nil
+	}
+	return d.updateVerifier(b)
+}
+
+// decryptFrames decrypts frames and appends their plaintext to dst in order.
+// Frames are opened in sequence, or by up to concurrency goroutines into their
+// own regions of dst. The first frame failure stops outstanding work and is
+// returned. Progress is reported in sequence order.
+//
+// Verifier is not updated, callers update it with the serialized body.
+func (d *decrypter) decryptFrames(dst []byte, frames []encryptedFrame) ([]byte, error) {
+	// plaintext length of each frame equals its encrypted content length
+	start := len(dst)
+	offsets := make([]int, len(frames)+1)
+	offsets[0] = start
+	for i, frame := range frames {
+		offsets[i+1] = offsets[i] + len(frame.EncryptedContent())
+	}
+	dst = grow(dst, offsets[len(frames)]-start)
+
+	err := forEachFrame(len(frames), d.concurrency, func(i int) error {
+		if err := checkContext(d.ctx); err != nil {
+			return err
 		}
+		_, err := d.openFrame(dst[offsets[i]:offsets[i]:offsets[i+1]], frames[i])
+		return err
+	}, func(i int) {
+		d.progress.report(frames[i].SequenceNumber(), offsets[i+1]-offsets[i])
+	})
+	if err != nil {
+		return nil, err
 	}
+	return dst, nil
+}
 
-	if plaintext.Len() != readBytes {
-		return nil, fmt.Errorf("malformed body message size")
+// decryptFrame decrypts a single frame and updates verifier with frame bytes.
+func (d *decrypter) decryptFrame(frame encryptedFrame) ([]byte, error) {
+	b, err := d.openFrame(nil, frame)
+	if err != nil {
+		return nil, err
+	}
+	// if alg is signing, write each frame bytes to verifier to update message hash
+	if d.verifier != nil {
+		if err := d.updateVerifier(frame.Bytes()); err != nil {
+			return nil, err
+		}
 	}
+	return b, nil
+}
 
-	var plaintextData []byte
-	plaintextData = make([]byte, plaintext.Len())
+// openFrame decrypts a single frame and appends plaintext to dst,
+// it is safe for concurrent use.
+func (d *decrypter) openFrame(dst []byte, frame encryptedFrame) ([]byte, error) {
+	contentString, errAad := bodyaad.BodyAAD.ContentString(suite.FramedContent, frame.IsFinal())
+	if errAad != nil {
+		return nil, fmt.Errorf("body aad error: %w", errAad)
+	}
+	associatedData := bodyaad.BodyAAD.ContentAADBytes(
+		d.header.MessageID,
+		contentString,
+		frame.SequenceNumber(),
+		len(frame.EncryptedContent()),
+	)
+	b, errAead := d.aeadDecrypter.DecryptTo(
+		dst,
+		d._derivedDataKey,
+		frame.IV(),
+		frame.EncryptedContent(),
+		frame.AuthenticationTag(),
+		associatedData,
+	)
+	if errAead != nil {
+		return nil, fmt.Errorf("decrypt frame error: %w", errAead)
+	}
+	return b, nil
+}
 
-	wb, err := plaintext.Read(plaintextData)
+// decryptNonFramedContent decrypts deserialized non-framed body
+// and appends plaintext to dst.
+func (d *decrypter) decryptNonFramedContent(dst []byte, body encryptedContent) ([]byte, error) {
+	contentString, err := bodyaad.BodyAAD.ContentString(suite.NonFramedContent, true)
 	if err != nil {
-		return nil, fmt.Errorf("malformed body message size: %w", err)
+		return nil, fmt.Errorf("body aad error: %w", err)
 	}
-	if wb != readBytes {
-		return nil, fmt.Errorf("malformed body message size")
+	associatedData := bodyaad.BodyAAD.ContentAADBytes(
+		d.header.MessageID,
+		contentString,
+		nonFramedSequenceNumber,
+		len(body.EncryptedContent()),
+	)
+	plaintext, err := d.aeadDecrypter.DecryptTo(
+		dst,
+		d._derivedDataKey,
+		body.IV(),
+		body.EncryptedContent(),
+		body.AuthenticationTag(),
+		associatedData,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt body error: %w", err)
 	}
-	plaintext.Reset()
 
-	return plaintextData, nil
+	return plaintext, nil
+}
+
+// destroy wipes the derived data key from memory. It is safe to call more
+// than once, decrypter must not be used afterwards.
+func (d *decrypter) destroy() {
+	zeroize.Bytes(d._derivedDataKey)
+	d._derivedDataKey = nil
 }
 
 func (d *decrypter) updateVerifier(b []byte) error {
@@ -4,10 +4,9 @@
 package crypto
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"math"
+	"io"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/crypto/signature"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/helpers/bodyaad"
@@ -17,57 +16,190 @@ import (
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/keyderivation"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/rand"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 func (e *encrypter) encrypt(ctx context.Context, source []byte, ec suite.EncryptionContext) ([]byte, *serialization.MessageHeader, error) {
-	var b []byte
-	b = make([]byte, len(source))
-	copy(b, source)
-	if len(source) == 0 || len(b) == 0 {
-		return nil, nil, fmt.Errorf("empty source")
+	return e.encryptTo(ctx, nil, source, ec)
+}
+
+// encryptTo encrypts source and appends the message to dst. Frames are sealed
+// from source directly into dst, plaintext is not buffered.
+func (e *encrypter) encryptTo(ctx context.Context, dst, source []byte, ec suite.EncryptionContext) ([]byte, *serialization.MessageHeader, error) {
+	defer e.destroy()
+
+	// empty source produces a single empty final frame, or an empty non-framed body
+	output := &appendBuffer{b: spareFor(dst, source)}
+	if err := e.start(ctx, output, ec, len(source)); err != nil {
+		return nil, nil, err
+	}
+
+	if e.contentType == suite.FramedContent {
+		full := len(source) - len(source)%e.frameLength
+		if err := e.write(source[:full]); err != nil {
+			return nil, nil, fmt.Errorf("encrypt error: %w", err)
+		}
+		source = source[full:]
+	}
+	// the rest is sealed by close as the final frame or non-framed body
+	e.plaintextBuf = source
+
+	if err := e.close(); err != nil {
+		return nil, nil, err
+	}
+
+	return output.b, e.header, nil
+}
+
+// appendBuffer is an [io.Writer] appending to a byte slice. When encrypter
+// output is appendBuffer, frames are sealed in place at the end of the slice.
+type appendBuffer struct {
+	b []byte
+}
+
+func (ab *appendBuffer) Write(p []byte) (int, error) {
+	ab.b = append(ab.b, p...)
+	return len(p), nil
+}
+
+// grow extends b by n bytes, reallocating only if capacity of b is not enough.
+func grow(b []byte, n int) []byte {
+	if cap(b)-len(b) < n {
+		nb := make([]byte, len(b), 2*cap(b)+n)
+		copy(nb, b)
+		b = nb
+	}
+	return b[:len(b)+n]
+}
+
+// start obtains encryption materials and writes the message header
+// and header authentication into output.
+//
+// plaintextLength is passed to materials manager, -1 if unknown.
+func (e *encrypter) start(ctx context.Context, output io.Writer, ec suite.EncryptionContext, plaintextLength int) error {
+	if output == nil {
+		return fmt.Errorf("output must not be nil")
+	}
+	e.ctx = ctx
+	e.output = output
+	if plaintextLength >= 0 {
+		e.progress.total = int64(plaintextLength)
 	}
-	buf := bytes.NewBuffer(b)
-	if err := e.prepareMessage(ctx, buf, ec); err != nil {
-		return nil, nil, fmt.Errorf("prepare message error: %w", err)
+	e.dst, _ = output.(*appendBuffer)
+
+	if err := e.prepareMessage(ctx, ec, plaintextLength); err != nil {
+		return fmt.Errorf("prepare message error: %w", err)
 	}
 
 	if err := e.generateHeaderAuth(); err != nil {
-		return nil, nil, fmt.Errorf("encrypt error: %w", err)
+		return fmt.Errorf("encrypt error: %w", err)
+	}
+
+	if e.contentType == suite.FramedContent {
+		body, err := serialization.MessageBody.NewBody(e.algorithm, e.frameLength)
+		if err != nil {
+			return fmt.Errorf("body error: %w", err)
+		}
+		e.body = body
+		e.seqNum = 1
+	}
+
+	return nil
+}
+
+// write encrypts plaintext p. Each frame is written into output as soon as
+// frameLength bytes of plaintext are available, the rest stays buffered
+// until the next write or close.
+//
+// Non-framed content is buffered as a whole until close.
+func (e *encrypter) write(p []byte) error {
+	if e.contentType == suite.NonFramedContent {
+		if uint64(len(e.plaintextBuf)+len(p)) > serialization.MaxNonFramedContentLength {
+			return fmt.Errorf("plaintext too large for non-framed content")
+		}
+		e.plaintextBuf = append(e.plaintextBuf, p...)
+		return nil
+	}
+
+	if len(e.plaintextBuf) > 0 {
+		n := e.frameLength - len(e.plaintextBuf)
+		if n > len(p) {
+			n = len(p)
+		}
+		e.plaintextBuf = append(e.plaintextBuf, p[:n]...)
+		p = p[n:]
+		if len(e.plaintextBuf) < e.frameLength {
+			return nil
+		}
+		if err := e.writeFrame(e.plaintextBuf, false); err != nil {
+			return err
+		}
+		e.plaintextBuf = e.plaintextBuf[:0]
+	}
+
+	for len(p) >= e.frameLength {
+		if err := checkContext(e.ctx); err != nil {
+			return err
+		}
+		n := len(p) / e.frameLength
+		if n > e.batchFrames() {
+			n = e.batchFrames()
+		}
+		frames := make([][]byte, n)
+		for i := range frames {
+			frames[i] = p[i*e.frameLength : (i+1)*e.frameLength]
+		}
+		if err := e.writeFrames(frames); err != nil {
+			return err
+		}
+		p = p[n*e.frameLength:]
 	}
 
-	if err := e.encryptBody(buf); err != nil {
-		return nil, nil, fmt.Errorf("encrypt error: %w", err)
+	if len(p) > 0 {
+		if e.plaintextBuf == nil {
+			e.plaintextBuf = make([]byte, 0, e.frameLength)
+		}
+		e.plaintextBuf = append(e.plaintextBuf, p...)
 	}
 
-	// TODO andrew clean up derivedDataKey
+	return nil
+}
+
+// close encrypts buffered plaintext as the final frame, or as a single block
+// for non-framed content, and writes the footer if the algorithm is signing.
+func (e *encrypter) close() error {
+	if err := checkContext(e.ctx); err != nil {
+		return err
+	}
+	if e.contentType == suite.NonFramedContent {
+		if err := e.encryptNonFramedBody(e.plaintextBuf); err != nil {
+			return fmt.Errorf("encrypt error: %w", err)
+		}
+	} else {
+		if err := e.writeFrame(e.plaintextBuf, true); err != nil {
+			return fmt.Errorf("encrypt error: %w", err)
+		}
+	}
+	e.plaintextBuf = nil
 
 	if e.signer != nil {
 		sign, err := e.signer.Sign()
 		if err != nil {
-			return nil, nil, fmt.Errorf("encrypt sign error: %w", err)
+			return fmt.Errorf("encrypt sign error: %w", err)
 		}
 		footer, err := serialization.MessageFooter.NewFooter(e.algorithm, sign)
 		if err != nil {
-			return nil, nil, fmt.Errorf("encrypt sign error: %w", err)
+			return fmt.Errorf("encrypt sign error: %w", err)
 		}
 		if errBuf := e.updateCiphertextBuf(footer.Bytes()); errBuf != nil {
-			return nil, nil, errBuf // already wrapped in updateCiphertextBuf
+			return errBuf // already wrapped in updateCiphertextBuf
 		}
 	}
 
-	var ciphertext []byte
-	ciphertext = make([]byte, e.ciphertextBuf.Len())
-	_, err := e.ciphertextBuf.Read(ciphertext)
-	if err != nil {
-		return nil, nil, fmt.Errorf("ciphertext read error: %w", err)
-	}
-
-	e.ciphertextBuf.Reset()
-
-	return ciphertext, e.header, nil
+	return nil
 }
 
-func (e *encrypter) prepareMessage(ctx context.Context, plaintextBuffer *bytes.Buffer, ec suite.EncryptionContext) error {
+func (e *encrypter) prepareMessage(ctx context.Context, ec suite.EncryptionContext, plaintextLength int) error {
 	if err := policy.Commitment.ValidatePolicyOnEncrypt(e.config.CommitmentPolicy(), e.algorithm); err != nil {
 		return err // just return err
 	}
@@ -75,13 +207,15 @@ func (e *encrypter) prepareMessage(ctx context.Context, plaintextBuffer *bytes.B
 	emr := model.EncryptionMaterialsRequest{
 		EncryptionContext: ec,
 		Algorithm:         e.algorithm,
-		PlaintextLength:   plaintextBuffer.Len(),
+		PlaintextLength:   plaintextLength,
 	}
 
 	encMaterials, err := e.cmm.GetEncryptionMaterials(ctx, emr)
 	if err != nil {
 		return fmt.Errorf("encrypt materials: %w", err)
 	}
+	// signing key is needed until the footer is written, materials are destroyed with encrypter
+	e.materials = encMaterials
 	if len(encMaterials.EncryptedDataKeys()) > e.config.MaxEncryptedDataKeys() {
 		return fmt.Errorf("materials: max encrypted data keys exceeded")
 	}
@@ -107,7 +241,6 @@ func (e *encrypter) prepareMessage(ctx context.Context, plaintextBuffer *bytes.B
 		return fmt.Errorf("key derivation failed: %w", err)
 	}
 
-	// TODO andrew clean up this after use
 	e._derivedDataKey = derivedDataKey
 
 	if errHeader := e.generateHeader(messageID, encMaterials); errHeader != nil {
@@ -118,16 +251,25 @@ func (e *encrypter) prepareMessage(ctx context.Context, plaintextBuffer *bytes.B
 }
 
 func (e *encrypter) generateHeader(messageID []byte, encMaterials model.EncryptionMaterial) error {
-	aadData := serialization.AAD.NewAADWithEncryptionContext(encMaterials.EncryptionContext())
+	// required encryption context is authenticated but not stored in the header
+	storedEC, authOnlyEC, err := splitEncryptionContext(encMaterials.EncryptionContext(), requiredEncryptionContextKeys(encMaterials))
+	if err != nil {
+		return err
+	}
+	e.authOnlyEC = authOnlyEC
+	aadData := serialization.AAD.NewAADWithEncryptionContext(storedEC)
 
 	edks, err := serialization.EDK.FromEDKs(encMaterials.EncryptedDataKeys())
 	if err != nil {
 		return fmt.Errorf("EDK error: %w", err)
 	}
 
-	commitmentKey, err := keyderivation.CalculateCommitmentKey(encMaterials.DataEncryptionKey().DataKey(), e.algorithm, messageID)
-	if err != nil {
-		return fmt.Errorf("calculate commitment key error: %w", err)
+	var commitmentKey []byte
+	if e.algorithm.IsCommitting() {
+		commitmentKey, err = keyderivation.CalculateCommitmentKey(encMaterials.DataEncryptionKey().DataKey(), e.algorithm, messageID)
+		if err != nil {
+			return fmt.Errorf("calculate commitment key error: %w", err)
+		}
 	}
 
 	params := serialization.MessageHeaderParams{
@@ -135,7 +277,7 @@ func (e *encrypter) generateHeader(messageID []byte, encMaterials model.Encrypti
 		MessageID:          messageID,
 		AADData:            aadData,
 		EncryptedDataKeys:  edks,
-		ContentType:        suite.FramedContent,
+		ContentType:        e.contentType,
 		FrameLength:        e.frameLength,
 		AlgorithmSuiteData: commitmentKey,
 	}
@@ -154,11 +296,15 @@ func (e *encrypter) generateHeader(messageID []byte, encMaterials model.Encrypti
 }
 
 func (e *encrypter) generateHeaderAuth() error {
-	headerAuthTag, err := e.aeadEncrypter.GenerateHeaderAuth(e._derivedDataKey, e.header.Bytes())
+	headerAuthTag, err := e.aeadEncrypter.GenerateHeaderAuth(e._derivedDataKey, e.header.AuthenticationData(e.authOnlyEC))
 	if err != nil {
 		return fmt.Errorf("header auth error: %w", err)
 	}
 	headerAuthData, err := serialization.MessageHeaderAuth.New(headerAuthTag)
+	if e.algorithm.MessageFormatVersion == suite.MessageFormatVersion1 {
+		// format version 1 serializes header auth IV along with auth tag
+		headerAuthData, err = serialization.MessageHeaderAuth.NewWithIV(e.aeadEncrypter.ConstructIV(0), headerAuthTag)
+	}
 	if err != nil {
 		return fmt.Errorf("header auth serialize error: %w", err)
 	}
@@ -168,68 +314,241 @@ func (e *encrypter) generateHeaderAuth() error {
 	return nil
 }
 
-func (e *encrypter) encryptBody(plaintextBuffer *bytes.Buffer) error {
-	body, errBody := serialization.MessageBody.NewBody(e.header.AlgorithmSuite, e.frameLength)
-	if errBody != nil {
-		return fmt.Errorf("body error: %w", errBody)
+// writeFrame encrypts plaintext as a frame with the next sequence number
+// and writes the frame into output.
+func (e *encrypter) writeFrame(plaintext []byte, isFinal bool) error {
+	if e.dst != nil {
+		return e.sealFrames(isFinal, [][]byte{plaintext})
+	}
+	if e.seqNum > suite.MaxFrameSequenceNumber {
+		return fmt.Errorf("frame sequence number exceeds maximum")
+	}
+	ciphertext, authTag, err := e.encryptFrame(e.seqNum, isFinal, plaintext)
+	if err != nil {
+		return err
+	}
+	return e.appendFrame(isFinal, ciphertext, authTag)
+}
+
+// batchFrames returns the number of full frames encrypted at once.
+func (e *encrypter) batchFrames() int {
+	if e.concurrency <= 1 {
+		return 1
 	}
+	return e.concurrency * framesPerWorker
+}
 
-	framesToRead := math.Ceil(float64(plaintextBuffer.Len()) / float64(e.frameLength))
+// writeFrames encrypts full non-final frames with the next sequence numbers.
+// Frames are sealed by up to concurrency goroutines, and written into output
+// in sequence order, so the output is the same as with writeFrame.
+func (e *encrypter) writeFrames(frames [][]byte) error {
+	if e.dst != nil {
+		return e.sealFrames(false, frames)
+	}
+	if len(frames) == 1 {
+		return e.writeFrame(frames[0], false)
+	}
+	if e.seqNum+len(frames)-1 > suite.MaxFrameSequenceNumber {
+		return fmt.Errorf("frame sequence number exceeds maximum")
+	}
 
-	// Check if an extra final frame is needed when the plaintextBuffer
-	//  length is exactly divisible by the frame length
-	if plaintextBuffer.Len()%e.frameLength == 0 {
-		framesToRead++
+	type sealedFrame struct {
+		ciphertext, authTag []byte
+	}
+	sealed := make([]sealedFrame, len(frames))
+	err := forEachFrame(len(frames), e.concurrency, func(i int) error {
+		if err := checkContext(e.ctx); err != nil {
+			return err
+		}
+		ciphertext, authTag, err := e.encryptFrame(e.seqNum+i, false, frames[i])
+		if err != nil {
+			return err
+		}
+		sealed[i] = sealedFrame{ciphertext: ciphertext, authTag: authTag}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
 	}
 
-	calculateFrame := func() (int, bool) {
-		if plaintextBuffer.Len() > e.frameLength { //nolint:gocritic
-			return e.frameLength, false
-		} else if plaintextBuffer.Len() == e.frameLength {
-			return e.frameLength, false
-		} else { //nolint:revive
-			return plaintextBuffer.Len(), true
+	for _, f := range sealed {
+		if err := e.appendFrame(false, f.ciphertext, f.authTag); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// appendFrame adds sealed frame with the next sequence number to the body
+// and writes the frame into output.
+func (e *encrypter) appendFrame(isFinal bool, ciphertext, authTag []byte) error {
+	if errFrame := e.body.AddFrame(isFinal, e.seqNum, e.aeadEncrypter.ConstructIV(e.seqNum), len(ciphertext), ciphertext, authTag); errFrame != nil {
+		return fmt.Errorf("body frame error: %w", errFrame)
+	}
+	e.progress.report(e.seqNum, len(ciphertext))
+	e.seqNum++
 
-	for seqNum := 1; seqNum <= int(framesToRead); seqNum++ {
-		bytesToRead, isFinal := calculateFrame()
-		plaintext := plaintextBuffer.Next(bytesToRead)
-		ciphertext, authTag, err := e.encryptFrame(seqNum, isFinal, plaintext)
+	return e.updateBuffers(e.body.Flush())
+}
+
+// sealFrames encrypts frames with the next sequence numbers in place at the end
+// of dst. Serialized frame lengths are known upfront, so frames are sealed into
+// their own regions of dst by up to concurrency goroutines.
+func (e *encrypter) sealFrames(isFinal bool, frames [][]byte) error {
+	if e.seqNum+len(frames)-1 > suite.MaxFrameSequenceNumber {
+		return fmt.Errorf("frame sequence number exceeds maximum")
+	}
+
+	regular, final := serialization.FrameOverheads(e.algorithm)
+	overhead := int(regular)
+	if isFinal {
+		overhead = int(final)
+	}
+	start := len(e.dst.b)
+	offsets := make([]int, len(frames)+1)
+	offsets[0] = start
+	for i, frame := range frames {
+		offsets[i+1] = offsets[i] + overhead + len(frame)
+	}
+	e.dst.b = grow(e.dst.b, offsets[len(frames)]-start)
+
+	err := forEachFrame(len(frames), e.concurrency, func(i int) error {
+		if err := checkContext(e.ctx); err != nil {
+			return err
+		}
+		seqNum := e.seqNum + i
+		region := e.dst.b[offsets[i]:offsets[i]:offsets[i+1]]
+		iv := e.aeadEncrypter.ConstructIV(seqNum)
+		region = serialization.MessageBody.AppendFrameHeader(region, isFinal, seqNum, iv, len(frames[i]))
+		region, err := e.sealFrame(region, seqNum, isFinal, frames[i])
 		if err != nil {
 			return err
 		}
-		if errFrame := body.AddFrame(isFinal, seqNum, e.aeadEncrypter.ConstructIV(seqNum), len(plaintext), ciphertext, authTag); errFrame != nil {
-			return fmt.Errorf("body frame error: %w", errFrame)
+		if len(region) != offsets[i+1]-offsets[i] {
+			return fmt.Errorf("frame %d length mismatch", seqNum)
+		}
+		return nil
+	}, func(i int) {
+		e.progress.report(e.seqNum+i, len(frames[i]))
+	})
+	if err != nil {
+		return err
+	}
+	e.seqNum += len(frames)
+
+	if e.signer != nil {
+		if _, err := e.signer.Write(e.dst.b[start:]); err != nil {
+			return fmt.Errorf("signer write error: %w", err)
+		}
+	}
+	return nil
+}
+
+// encryptNonFramedBody encrypts the whole plaintext as a single block.
+func (e *encrypter) encryptNonFramedBody(plaintext []byte) error {
+	if uint64(len(plaintext)) > serialization.MaxNonFramedContentLength {
+		return fmt.Errorf("plaintext too large for non-framed content")
+	}
+
+	contentString, err := bodyaad.BodyAAD.ContentString(suite.NonFramedContent, true)
+	if err != nil {
+		return fmt.Errorf("body aad error: %w", err)
+	}
+	associatedData := bodyaad.BodyAAD.ContentAADBytes(
+		e.header.MessageID,
+		contentString,
+		nonFramedSequenceNumber,
+		len(plaintext),
+	)
+	iv := e.aeadEncrypter.ConstructIV(nonFramedSequenceNumber)
+	if e.dst != nil {
+		start := len(e.dst.b)
+		b := serialization.MessageBody.AppendNonFramedHeader(e.dst.b, iv, len(plaintext))
+		b, err = e.aeadEncrypter.EncryptTo(b, e._derivedDataKey, iv, plaintext, associatedData)
+		if err != nil {
+			return fmt.Errorf("encrypt body error: %w", err)
+		}
+		e.dst.b = b
+		if e.signer != nil {
+			if _, err := e.signer.Write(b[start:]); err != nil {
+				return fmt.Errorf("signer write error: %w", err)
+			}
 		}
+		e.progress.report(nonFramedSequenceNumber, len(plaintext))
+		return nil
+	}
+	ciphertext, authTag, err := e.aeadEncrypter.Encrypt(
+		e._derivedDataKey,
+		iv,
+		plaintext,
+		associatedData,
+	)
+	if err != nil {
+		return fmt.Errorf("encrypt body error: %w", err)
+	}
+
+	body, err := serialization.MessageBody.NewNonFramedBody(e.algorithm, iv, ciphertext, authTag)
+	if err != nil {
+		return fmt.Errorf("body error: %w", err)
 	}
 
-	return e.updateBuffers(body.Bytes())
+	if err := e.updateBuffers(body.Bytes()); err != nil {
+		return err
+	}
+	e.progress.report(nonFramedSequenceNumber, len(plaintext))
+	return nil
 }
 
 func (e *encrypter) encryptFrame(seqNum int, isFinal bool, plaintext []byte) ([]byte, []byte, error) {
+	sealed, err := e.sealFrame(nil, seqNum, isFinal, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	tagStart := len(sealed) - e.algorithm.EncryptionSuite.AuthLen
+	return sealed[:tagStart], sealed[tagStart:], nil
+}
+
+// sealFrame encrypts frame plaintext and appends ciphertext followed by
+// the authentication tag to dst. It is safe for concurrent use.
+func (e *encrypter) sealFrame(dst []byte, seqNum int, isFinal bool, plaintext []byte) ([]byte, error) {
+	contentString, err := bodyaad.BodyAAD.ContentString(suite.FramedContent, isFinal)
+	if err != nil {
+		return nil, fmt.Errorf("body aad error: %w", err)
+	}
 	associatedData := bodyaad.BodyAAD.ContentAADBytes(
 		e.header.MessageID,
-		bodyaad.BodyAAD.ContentString(suite.FramedContent, isFinal),
+		contentString,
 		seqNum,
 		len(plaintext),
 	)
-	ciphertext, authTag, err := e.aeadEncrypter.Encrypt(
+	sealed, err := e.aeadEncrypter.EncryptTo(
+		dst,
 		e._derivedDataKey,
 		e.aeadEncrypter.ConstructIV(seqNum),
 		plaintext,
 		associatedData,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("encrypt frame error: %w", err)
+		return nil, fmt.Errorf("encrypt frame error: %w", err)
+	}
+	return sealed, nil
+}
+
+// destroy wipes the derived data key and encryption materials from memory.
+// It is safe to call more than once, encrypter must not be used afterwards.
+func (e *encrypter) destroy() {
+	zeroize.Bytes(e._derivedDataKey)
+	e._derivedDataKey = nil
+	if e.materials != nil {
+		destroyMaterials(e.materials)
+		e.materials = nil
 	}
-	return ciphertext, authTag, nil
 }
 
 func (e *encrypter) updateCiphertextBuf(b []byte) error {
-	_, err := e.ciphertextBuf.Write(b)
+	_, err := e.output.Write(b)
 	if err != nil {
-		return fmt.Errorf("ciphertext buffer write error: %w", err)
+		return fmt.Errorf("ciphertext write error: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,84 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/serialization"
+)
+
+// MessageIterator decrypts consecutive messages concatenated in a byte stream.
+//
+// Each message is decrypted as a whole, its plaintext is returned only after
+// the signature, if any, is verified. Any error is sticky.
+type MessageIterator struct {
+	ctx    context.Context
+	config clientconfig.ClientConfig
+	src    io.Reader
+	cmm    model.CryptoMaterialsManager
+	params DecryptParams
+	err    error
+}
+
+// NewMessageIterator returns a MessageIterator reading messages from src.
+// Messages are read field by field, src should be buffered.
+func NewMessageIterator(ctx context.Context, config clientconfig.ClientConfig, src io.Reader, cmm model.CryptoMaterialsManager, params DecryptParams) (*MessageIterator, error) {
+	if src == nil {
+		return nil, fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, fmt.Errorf("source must not be nil")))
+	}
+	return &MessageIterator{ctx: ctx, config: config, src: src, cmm: cmm, params: params}, nil
+}
+
+// Next reads and decrypts the next message. It returns the plaintext and the
+// message header, or [io.EOF] if src has no more messages.
+//
+// A message truncated at any point, including trailing bytes which are not
+// a complete message, is an error.
+func (it *MessageIterator) Next() ([]byte, *serialization.MessageHeader, error) {
+	if it.err != nil {
+		return nil, nil, it.err
+	}
+	plaintext, header, err := it.next()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			err = fmt.Errorf("SDK error: %w", errors.Join(ErrDecryption, err))
+		}
+		it.err = err
+		return nil, nil, err
+	}
+	return plaintext, header, nil
+}
+
+func (it *MessageIterator) next() ([]byte, *serialization.MessageHeader, error) {
+	dec := newDecrypter(it.config, it.cmm, it.params)
+	defer dec.destroy()
+	if err := dec.start(it.ctx, it.src); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, io.EOF
+		}
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	for {
+		plaintext, final, err := dec.next(it.src)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf.Write(plaintext)
+		if final {
+			break
+		}
+	}
+	if err := dec.finish(it.src); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), dec.header, nil
+}
@@ -0,0 +1,99 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/clientconfig"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/materials"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/providers/rawprovider"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func newTestCMM(t *testing.T) model.CryptoMaterialsManager {
+	t.Helper()
+	rawProvider, err := rawprovider.NewWithOpts(
+		"raw",
+		rawprovider.WithStaticKey("static1", []byte("superSecureKeySuperSecureKey1234")),
+	)
+	require.NoError(t, err)
+	cmm, err := materials.NewDefault(rawProvider)
+	require.NoError(t, err)
+	return cmm
+}
+
+func newTestConfig(t *testing.T) clientconfig.ClientConfig {
+	t.Helper()
+	cfg, err := clientconfig.NewConfig()
+	require.NoError(t, err)
+	return *cfg
+}
+
+func Test_MessageIterator(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmm := newTestCMM(t)
+	ec := suite.EncryptionContext{"purpose": "test"}
+
+	plaintexts := [][]byte{
+		bytes.Repeat([]byte("a"), 300),
+		{},
+		bytes.Repeat([]byte("b"), 128),
+	}
+	var stream []byte
+	for i, p := range plaintexts {
+		alg := suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
+		if i%2 == 1 {
+			alg = suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY
+		}
+		ciphertext, _, err := Encrypt(context.Background(), cfg, p, ec, cmm, alg, 128)
+		require.NoError(t, err)
+		stream = append(stream, ciphertext...)
+	}
+
+	tests := []struct {
+		name     string
+		stream   []byte
+		wantN    int
+		wantLast error
+	}{
+		{"concatenated", stream, len(plaintexts), io.EOF},
+		{"empty_stream", nil, 0, io.EOF},
+		{"trailing_bytes", append(append([]byte{}, stream...), 0x02, 0x05), len(plaintexts), ErrDecryption},
+		{"truncated_message", stream[:len(stream)-1], len(plaintexts) - 1, ErrDecryption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := NewMessageIterator(context.Background(), cfg, bufio.NewReader(bytes.NewReader(tt.stream)), cmm, DecryptParams{})
+			require.NoError(t, err)
+
+			for i := 0; i < tt.wantN; i++ {
+				got, header, err := it.Next()
+				require.NoError(t, err)
+				assert.Equal(t, string(plaintexts[i]), string(got))
+				assert.NotNil(t, header)
+			}
+			_, _, err = it.Next()
+			assert.ErrorIs(t, err, tt.wantLast)
+
+			// error is sticky
+			_, _, errAgain := it.Next()
+			assert.Equal(t, err, errAgain)
+		})
+	}
+}
+
+func Test_NewMessageIterator_NilSource(t *testing.T) {
+	it, err := NewMessageIterator(context.Background(), newTestConfig(t), nil, newTestCMM(t), DecryptParams{})
+	assert.ErrorIs(t, err, ErrDecryption)
+	assert.Nil(t, it)
+}
@@ -0,0 +1,76 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// forEachFrame calls fn for frame indexes 0 to n-1, in order if workers is 1 or
+// less, or by up to workers goroutines otherwise. The first fn failure stops
+// outstanding work and is returned.
+//
+// done, if not nil, is called in the calling goroutine in index order, as soon as
+// fn succeeded for the index and all lower indexes.
+func forEachFrame(n, workers int, fn func(i int) error, done func(i int)) error {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+			if done != nil {
+				done(i)
+			}
+		}
+		return nil
+	}
+
+	var (
+		wg       sync.WaitGroup
+		next     atomic.Int64
+		failed   atomic.Bool
+		errOnce  sync.Once
+		frameErr error
+	)
+	completed := make(chan int, n)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := fn(i); err != nil {
+					errOnce.Do(func() { frameErr = err })
+					failed.Store(true)
+					return
+				}
+				completed <- i
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(completed)
+	}()
+
+	finished := make([]bool, n)
+	reported := 0
+	for i := range completed {
+		finished[i] = true
+		for reported < n && finished[reported] {
+			if done != nil {
+				done(reported)
+			}
+			reported++
+		}
+	}
+	return frameErr
+}
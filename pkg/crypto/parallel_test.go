@@ -0,0 +1,125 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_forEachFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		workers int
+	}{
+		{"empty", 0, 4},
+		{"serial", 10, 1},
+		{"serial_zero_workers", 10, 0},
+		{"single_frame", 1, 4},
+		{"parallel", 100, 4},
+		{"more_workers_than_frames", 3, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, tt.n)
+			var calls atomic.Int64
+			var reported []int
+			err := forEachFrame(tt.n, tt.workers, func(i int) error {
+				calls.Add(1)
+				got[i] = i * i
+				return nil
+			}, func(i int) {
+				// done is called with fn results visible
+				assert.Equal(t, i*i, got[i])
+				reported = append(reported, i)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.n), calls.Load())
+			for i := range got {
+				assert.Equal(t, i*i, got[i])
+			}
+			assert.Len(t, reported, tt.n)
+			for i, r := range reported {
+				assert.Equal(t, i, r)
+			}
+		})
+	}
+}
+
+func Test_forEachFrame_OutOfOrder(t *testing.T) {
+	// frame 0 completes only after frame 1, results still land at their index
+	// and done is reported in index order
+	frame1Done := make(chan struct{})
+	var mu sync.Mutex
+	var completed []int
+	var reported []int
+	results := make([][]byte, 2)
+
+	err := forEachFrame(2, 2, func(i int) error {
+		if i == 0 {
+			<-frame1Done
+		}
+		results[i] = []byte{byte(i)}
+		mu.Lock()
+		completed = append(completed, i)
+		mu.Unlock()
+		if i == 1 {
+			close(frame1Done)
+		}
+		return nil
+	}, func(i int) {
+		reported = append(reported, i)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0}, completed)
+	assert.Equal(t, []int{0, 1}, reported)
+	assert.Equal(t, [][]byte{{0}, {1}}, results)
+}
+
+func Test_forEachFrame_Failure(t *testing.T) {
+	errFrame := errors.New("frame error")
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"serial", 1},
+		{"parallel", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const n, failAt = 100, 10
+			var calls atomic.Int64
+			var reported []int
+			err := forEachFrame(n, tt.workers, func(i int) error {
+				calls.Add(1)
+				if i == failAt {
+					return errFrame
+				}
+				time.Sleep(time.Millisecond)
+				return nil
+			}, func(i int) {
+				reported = append(reported, i)
+			})
+			assert.ErrorIs(t, err, errFrame)
+			// later frames are not started once a frame failed
+			assert.Less(t, calls.Load(), int64(n))
+			// frames from the failed one on are never reported done
+			assert.LessOrEqual(t, len(reported), failAt)
+			for i, r := range reported {
+				assert.Equal(t, i, r)
+			}
+			if tt.workers == 1 {
+				assert.Equal(t, int64(failAt+1), calls.Load())
+				assert.Len(t, reported, failAt)
+			}
+		})
+	}
+}
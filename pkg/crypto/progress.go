@@ -0,0 +1,36 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+// Progress is reported after each frame is encrypted or decrypted.
+// Non-framed content is reported once, as a single frame.
+type Progress struct {
+	SequenceNumber int   // SequenceNumber is the sequence number of the frame.
+	Bytes          int64 // Bytes is the number of plaintext bytes processed so far.
+	Total          int64 // Total is the plaintext length, -1 if unknown.
+}
+
+// ProgressFunc receives Progress in frame sequence order. It is called
+// synchronously from the goroutine that calls the SDK and should return quickly.
+type ProgressFunc func(Progress)
+
+// progressTracker accumulates processed bytes of a single message.
+type progressTracker struct {
+	fn        ProgressFunc
+	processed int64
+	total     int64
+}
+
+func newProgressTracker(fn ProgressFunc) progressTracker {
+	return progressTracker{fn: fn, total: -1}
+}
+
+// report adds n plaintext bytes of the frame with seqNum and calls fn, if any.
+func (p *progressTracker) report(seqNum, n int) {
+	if p.fn == nil {
+		return
+	}
+	p.processed += int64(n)
+	p.fn(Progress{SequenceNumber: seqNum, Bytes: p.processed, Total: p.total})
+}
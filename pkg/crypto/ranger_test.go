@@ -0,0 +1,192 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/serialization"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// recordingReaderAt records offsets of reads from src.
+type recordingReaderAt struct {
+	src     io.ReaderAt
+	offsets []int64
+}
+
+func (r *recordingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.offsets = append(r.offsets, off)
+	return r.src.ReadAt(p, off)
+}
+
+func Test_RangeDecrypter_ReadAt(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmm := newTestCMM(t)
+	const frameLength = 128
+
+	plaintext := make([]byte, 5*frameLength+17)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+	ciphertext, _, err := Encrypt(context.Background(), cfg, plaintext, nil, cmm, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, frameLength)
+	require.NoError(t, err)
+
+	rd, header, err := NewRangeDecrypter(context.Background(), cfg, bytes.NewReader(ciphertext), int64(len(ciphertext)), cmm, DecryptParams{})
+	require.NoError(t, err)
+	assert.Equal(t, frameLength, header.FrameLength)
+	assert.Equal(t, int64(len(plaintext)), rd.Size())
+
+	tests := []struct {
+		name    string
+		off     int64
+		n       int
+		wantN   int
+		wantErr error
+	}{
+		{"within_frame", 10, 20, 20, nil},
+		{"frame_boundary", frameLength - 5, 10, 10, nil},
+		{"several_frames", 100, 3 * frameLength, 3 * frameLength, nil},
+		{"final_frame", 5 * frameLength, 17, 17, nil},
+		{"past_end", int64(len(plaintext)) - 7, 10, 7, io.EOF},
+		{"at_end", int64(len(plaintext)), 10, 0, io.EOF},
+		{"empty", 50, 0, 0, nil},
+		{"negative_offset", -1, 10, 0, ErrDecryption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]byte, tt.n)
+			n, err := rd.ReadAt(p, tt.off)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantN, n)
+			if n > 0 {
+				assert.Equal(t, plaintext[tt.off:tt.off+int64(n)], p[:n])
+			}
+		})
+	}
+}
+
+func Test_RangeDecrypter_ReadsCoveringFramesOnly(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmm := newTestCMM(t)
+	const frameLength = 128
+
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 8*frameLength/16)
+	ciphertext, header, err := Encrypt(context.Background(), cfg, plaintext, nil, cmm, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, frameLength)
+	require.NoError(t, err)
+
+	// tamper the first frame, ranges not covering it are still readable
+	tampered := append([]byte{}, ciphertext...)
+	regular, _ := serialization.FrameOverheads(header.AlgorithmSuite)
+	firstFrame := int64(header.Len() + header.AlgorithmSuite.EncryptionSuite.AuthLen)
+	tampered[firstFrame+regular+frameLength-1] ^= 0x01 // first frame auth tag
+
+	src := &recordingReaderAt{src: bytes.NewReader(tampered)}
+	rd, _, err := NewRangeDecrypter(context.Background(), cfg, src, int64(len(tampered)), cmm, DecryptParams{})
+	require.NoError(t, err)
+
+	src.offsets = nil
+	p := make([]byte, frameLength)
+	n, err := rd.ReadAt(p, 3*frameLength+10)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext[3*frameLength+10:4*frameLength+10], p[:n])
+	// only the fourth and fifth frames are read
+	require.NotEmpty(t, src.offsets)
+	for _, off := range src.offsets {
+		assert.GreaterOrEqual(t, off, firstFrame+3*(regular+frameLength))
+		assert.Less(t, off, firstFrame+5*(regular+frameLength))
+	}
+
+	_, err = rd.ReadAt(p, 10)
+	assert.ErrorIs(t, err, ErrDecryption)
+}
+
+func Test_RangeDecrypter_Close(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmm := newTestCMM(t)
+
+	ciphertext, _, err := Encrypt(context.Background(), cfg, []byte("plaintext"), nil, cmm, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, 128)
+	require.NoError(t, err)
+	rd, _, err := NewRangeDecrypter(context.Background(), cfg, bytes.NewReader(ciphertext), int64(len(ciphertext)), cmm, DecryptParams{})
+	require.NoError(t, err)
+	derivedKey := rd.dec._derivedDataKey
+
+	require.NoError(t, rd.Close())
+	assert.Equal(t, make([]byte, len(derivedKey)), derivedKey)
+	_, err = rd.ReadAt(make([]byte, 4), 0)
+	assert.ErrorIs(t, err, ErrDecryption)
+
+	// second Close is a no-op
+	assert.NoError(t, rd.Close())
+}
+
+// cancelingReaderAt cancels ctx once src is read at or after offset.
+type cancelingReaderAt struct {
+	src    io.ReaderAt
+	offset int64
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.offset {
+		r.cancel()
+	}
+	return r.src.ReadAt(p, off)
+}
+
+func Test_RangeDecrypter_ContextCanceled(t *testing.T) {
+	cfg := newTestConfig(t)
+	cmm := newTestCMM(t)
+	const frameLength = 128
+
+	plaintext := bytes.Repeat([]byte{0x01}, 8*frameLength)
+	ciphertext, header, err := Encrypt(context.Background(), cfg, plaintext, nil, cmm, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, frameLength)
+	require.NoError(t, err)
+	regular, _ := serialization.FrameOverheads(header.AlgorithmSuite)
+	thirdFrame := int64(header.Len()+header.AlgorithmSuite.EncryptionSuite.AuthLen) + 2*(regular+frameLength)
+
+	tests := []struct {
+		name   string
+		params DecryptParams
+	}{
+		{"serial", DecryptParams{}},
+		{"parallel", DecryptParams{Concurrency: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// the final frame is read before any range, it does not cancel
+			src := &cancelingReaderAt{src: bytes.NewReader(ciphertext), offset: int64(len(ciphertext)), cancel: cancel}
+			rd, _, err := NewRangeDecrypter(ctx, cfg, src, int64(len(ciphertext)), cmm, tt.params)
+			require.NoError(t, err)
+
+			p := make([]byte, 2*frameLength)
+			n, err := rd.ReadAt(p, 0)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext[:n], p[:n])
+
+			// context is canceled while frames of the range are read
+			src.offset = thirdFrame
+			n, err = rd.ReadAt(make([]byte, 4*frameLength), frameLength)
+			assert.ErrorIs(t, err, ErrDecryption)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Zero(t, n)
+
+			// later ranges fail as well
+			_, err = rd.ReadAt(p, 0)
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
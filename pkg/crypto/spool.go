@@ -0,0 +1,77 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const spoolFilePattern = "aws-encryption-sdk-plaintext-*"
+
+var errBufferLimit = errors.New("plaintext exceeds buffer limit")
+
+// spool holds plaintext in memory up to limit. Beyond limit plaintext is
+// spilled into a temporary file in dir, or rejected when dir is empty.
+//
+// The temporary file contains plaintext, it is removed on Close.
+type spool struct {
+	limit int64
+	dir   string
+	mem   bytes.Buffer
+	file  *os.File
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && int64(s.mem.Len()+len(p)) > s.limit {
+		if s.dir == "" {
+			return 0, fmt.Errorf("%d bytes limit: %w", s.limit, errBufferLimit)
+		}
+		f, err := os.CreateTemp(s.dir, spoolFilePattern)
+		if err != nil {
+			return 0, fmt.Errorf("spill file error: %w", err)
+		}
+		s.file = f
+		if _, err := f.Write(s.mem.Bytes()); err != nil {
+			return 0, fmt.Errorf("spill file write error: %w", err)
+		}
+		s.mem.Reset()
+	}
+	if s.file != nil {
+		n, err := s.file.Write(p)
+		if err != nil {
+			return n, fmt.Errorf("spill file write error: %w", err)
+		}
+		return n, nil
+	}
+	return s.mem.Write(p)
+}
+
+// reader returns a reader of everything written to spool.
+func (s *spool) reader() (io.Reader, error) {
+	if s.file != nil {
+		if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("spill file seek error: %w", err)
+		}
+		return s.file, nil
+	}
+	return &s.mem, nil
+}
+
+func (s *spool) Close() error {
+	s.mem.Reset()
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	errClose := s.file.Close()
+	s.file = nil
+	if err := errors.Join(errClose, os.Remove(name)); err != nil {
+		return fmt.Errorf("spill file cleanup error: %w", err)
+	}
+	return nil
+}
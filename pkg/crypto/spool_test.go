@@ -0,0 +1,100 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crypto
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spillFiles(t *testing.T, dir string) []os.DirEntry {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	return entries
+}
+
+func Test_spool(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int64
+		withDir   bool
+		writes    []string
+		wantFiles int
+		wantErr   error
+	}{
+		{"memory", 8, true, []string{"abcd", "efgh"}, 0, nil},
+		{"spill", 8, true, []string{"abcd", "efgh", "ijkl"}, 1, nil},
+		{"spill_first_write", 2, true, []string{"abcd"}, 1, nil},
+		{"limit_without_dir", 8, false, []string{"abcd", "efgh", "ijkl"}, 0, errBufferLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := &spool{limit: tt.limit}
+			if tt.withDir {
+				s.dir = dir
+			}
+
+			var want []byte
+			var err error
+			for _, w := range tt.writes {
+				if _, err = s.Write([]byte(w)); err != nil {
+					break
+				}
+				want = append(want, w...)
+			}
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				r, err := s.reader()
+				require.NoError(t, err)
+				got, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+			assert.Len(t, spillFiles(t, dir), tt.wantFiles)
+
+			require.NoError(t, s.Close())
+			assert.Empty(t, spillFiles(t, dir))
+			assert.Nil(t, s.file)
+		})
+	}
+}
+
+func Test_decryptReader_RemovesSpillFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		abort func(r *decryptReader) error
+	}{
+		{"fail", func(r *decryptReader) error {
+			r.fail(errors.New("signature mismatch"))
+			return nil
+		}},
+		{"close", func(r *decryptReader) error {
+			return r.Close()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			derivedKey := []byte{0x01, 0x02, 0x03}
+			r := &decryptReader{dec: &decrypter{_derivedDataKey: derivedKey}, held: &spool{limit: 1, dir: dir}}
+			_, err := r.held.Write([]byte("plaintext"))
+			require.NoError(t, err)
+			require.Len(t, spillFiles(t, dir), 1)
+
+			require.NoError(t, tt.abort(r))
+			assert.Empty(t, spillFiles(t, dir))
+			assert.Nil(t, r.held)
+			assert.Equal(t, []byte{0, 0, 0}, derivedKey)
+		})
+	}
+}
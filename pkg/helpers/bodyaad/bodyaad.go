@@ -4,7 +4,8 @@
 package bodyaad
 
 import (
-	"github.com/rs/zerolog/log"
+	"errors"
+	"fmt"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/conv"
@@ -15,20 +16,26 @@ const (
 	lengthLen = int(8) // length as big-endian 64-bit unsigned integer
 )
 
+var errContentType = errors.New("unsupported content type")
+
 var BodyAAD bodyAAD //nolint:gochecknoglobals
 
 type bodyAAD struct{}
 
-func (bodyAAD) ContentString(contentType suite.ContentType, finalFrame bool) []byte {
-	if contentType != suite.FramedContent {
-		// TODO refactor to return ([]byte, error), dont panic here!
-		log.Info().Msgf("%v", suite.CommitmentPolicyForbidEncryptAllowDecrypt)
-		log.Panic().Msg("NonFramed content type not supported")
-	}
-	if finalFrame {
-		return []byte(suite.ContentAADFinalFrame)
+// ContentString returns body AAD content string for the given content type.
+// finalFrame is ignored for non-framed content.
+func (bodyAAD) ContentString(contentType suite.ContentType, finalFrame bool) ([]byte, error) {
+	switch contentType {
+	case suite.NonFramedContent:
+		return []byte(suite.ContentAADNonFramed), nil
+	case suite.FramedContent:
+		if finalFrame {
+			return []byte(suite.ContentAADFinalFrame), nil
+		}
+		return []byte(suite.ContentAADFrame), nil
+	default:
+		return nil, fmt.Errorf("%v: %w", contentType, errContentType)
 	}
-	return []byte(suite.ContentAADFrame)
 }
 
 func (bodyAAD) ContentAADBytes(messageID, contentString []byte, seqNum, length int) []byte {
@@ -18,17 +18,26 @@ func Test_bodyAAD_ContentString(t *testing.T) {
 		finalFrame  bool
 	}
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name    string
+		args    args
+		want    []byte
+		wantErr assert.ErrorAssertionFunc
 	}{
-		{"NotFinalFrame", args{suite.FramedContent, false}, []byte("AWSKMSEncryptionClient Frame")},
-		{"FinalFrame", args{suite.FramedContent, true}, []byte("AWSKMSEncryptionClient Final Frame")},
+		{"NotFinalFrame", args{suite.FramedContent, false}, []byte("AWSKMSEncryptionClient Frame"), assert.NoError},
+		{"FinalFrame", args{suite.FramedContent, true}, []byte("AWSKMSEncryptionClient Final Frame"), assert.NoError},
+		{"NonFramed", args{suite.NonFramedContent, false}, []byte("AWSKMSEncryptionClient Single Block"), assert.NoError},
+		{"NonFramedFinal", args{suite.NonFramedContent, true}, []byte("AWSKMSEncryptionClient Single Block"), assert.NoError},
+		{"UnknownContentType", args{suite.ContentType(0), false}, nil, assert.Error},
+		{"UnknownContentTypeFinal", args{suite.ContentType(3), true}, nil, assert.Error},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			bo := bodyAAD{}
-			if got := bo.ContentString(tt.args.contentType, tt.args.finalFrame); !reflect.DeepEqual(got, tt.want) {
+			got, err := bo.ContentString(tt.args.contentType, tt.args.finalFrame)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ContentString() = %v, want %v", got, tt.want)
 			}
 		})
@@ -36,8 +45,8 @@ func Test_bodyAAD_ContentString(t *testing.T) {
 }
 
 func Test_bodyAAD_ContentAADBytes(t *testing.T) {
-	contentString := BodyAAD.ContentString(suite.FramedContent, false)
-	contentStringFinal := BodyAAD.ContentString(suite.FramedContent, true)
+	contentString, _ := BodyAAD.ContentString(suite.FramedContent, false)
+	contentStringFinal, _ := BodyAAD.ContentString(suite.FramedContent, true)
 	type args struct {
 		messageID     []byte
 		contentString []byte
@@ -62,25 +71,3 @@ func Test_bodyAAD_ContentAADBytes(t *testing.T) {
 		})
 	}
 }
-
-func TestBodyAAD_ContentStringPanic(t *testing.T) {
-	tests := []struct {
-		name    string
-		f       func()
-		isPanic bool
-	}{
-		{"panicFinal", func() { BodyAAD.ContentString(suite.NonFramedContent, true) }, true},
-		{"panicNotFinal", func() { BodyAAD.ContentString(suite.NonFramedContent, false) }, true},
-		{"NotPanicFinal", func() { BodyAAD.ContentString(suite.FramedContent, true) }, false},
-		{"NotPanicNotFinal", func() { BodyAAD.ContentString(suite.FramedContent, false) }, false},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			if test.isPanic {
-				assert.Panics(t, test.f)
-			} else {
-				assert.NotPanics(t, test.f)
-			}
-		})
-	}
-}
@@ -16,7 +16,13 @@ type commitmentValidator struct{}
 var errCommitmentEncryptNonCommitted = errors.New("configuration conflict. Cannot encrypt due to CommitmentPolicy requiring only non-committed messages")
 var errCommitmentEncrypt = errors.New("configuration conflict. Cannot encrypt due to CommitmentPolicy requiring only committed messages")
 var errCommitmentDecrypt = errors.New("configuration conflict. Cannot decrypt due to CommitmentPolicy requiring only committed messages")
+var errCommitmentAlgorithm = errors.New("algorithm suite must not be nil")
 
+// ValidatePolicyOnEncrypt validates that the algorithm suite is allowed for encryption by the commitment policy.
+//
+//   - [suite.CommitmentPolicyForbidEncryptAllowDecrypt] allows only algorithm suites without key commitment.
+//   - [suite.CommitmentPolicyRequireEncryptAllowDecrypt] and [suite.CommitmentPolicyRequireEncryptRequireDecrypt]
+//     allow only algorithm suites with key commitment.
 func (commitmentValidator) ValidatePolicyOnEncrypt(policy suite.CommitmentPolicy, algorithm *suite.AlgorithmSuite) error {
 	if policy == suite.CommitmentPolicyForbidEncryptAllowDecrypt {
 		if algorithm != nil && algorithm.IsCommitting() {
@@ -31,7 +37,15 @@ func (commitmentValidator) ValidatePolicyOnEncrypt(policy suite.CommitmentPolicy
 	return nil
 }
 
+// ValidatePolicyOnDecrypt validates that the algorithm suite is allowed for decryption by the commitment policy.
+//
+//   - [suite.CommitmentPolicyForbidEncryptAllowDecrypt] and [suite.CommitmentPolicyRequireEncryptAllowDecrypt]
+//     allow any supported algorithm suite.
+//   - [suite.CommitmentPolicyRequireEncryptRequireDecrypt] allows only algorithm suites with key commitment.
 func (commitmentValidator) ValidatePolicyOnDecrypt(policy suite.CommitmentPolicy, algorithm *suite.AlgorithmSuite) error {
+	if algorithm == nil {
+		return errCommitmentAlgorithm
+	}
 	if policy == suite.CommitmentPolicyRequireEncryptRequireDecrypt && !algorithm.IsCommitting() {
 		return errCommitmentDecrypt
 	}
@@ -150,6 +150,7 @@ func (kp *KeyProvider) DecryptDataKeyFromList(ctx context.Context, MKP model.Mas
 	var dataKey model.DataKeyI
 
 	var errDecryptDataKey error
+	attempts := providers.DecryptAttemptsFromContext(ctx)
 	for i, edk := range encryptedDataKeys {
 		log.Trace().
 			Int("edkI", i).
@@ -167,6 +168,16 @@ func (kp *KeyProvider) DecryptDataKeyFromList(ctx context.Context, MKP model.Mas
 			errDecryptDataKey = fmt.Errorf("DecryptDataKeyFromList validate expected error: %w", errors.Join(providers.ErrMasterKeyProviderDecrypt, err))
 			continue
 		}
+		if !attempts.Acquire(edk.KeyProvider()) {
+			log.Trace().
+				Int("edkI", i).
+				Stringer("EDK", edk.KeyProvider()).
+				Str("MKP", MKP.ProviderID()).
+				Str("method", "DecryptDataKeyFromList").
+				Msg("DecryptDataKeyFromList max decrypt attempts reached, skipped")
+			errDecryptDataKey = fmt.Errorf("DecryptDataKeyFromList skipped EDK %v: %w", edk.KeyProvider(), errors.Join(providers.ErrMasterKeyProviderDecrypt, providers.ErrMaxDecryptAttempts))
+			continue
+		}
 		decryptedDataKey, errDecrypt := MKP.DecryptDataKey(ctx, edk, alg, ec)
 		if errDecrypt == nil {
 			dataKey = decryptedDataKey
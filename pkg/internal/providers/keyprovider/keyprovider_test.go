@@ -543,3 +543,31 @@ func TestKeyProvider_DecryptDataKeyFromList(t *testing.T) { //nolint:gocognit
 		})
 	}
 }
+
+func TestKeyProvider_DecryptDataKeyFromList_MaxDecryptAttempts(t *testing.T) {
+	kp := &KeyProvider{providerID: "raw", providerKind: types.Raw, vendOnDecrypt: false}
+	mkp := mocks.NewMockMasterKeyProvider(t)
+	mkp.EXPECT().ProviderID().Return("raw").Maybe()
+	mkp.EXPECT().ValidateProviderID(mock.Anything).Return(nil).Times(3)
+
+	var edks []model.EncryptedDataKeyI
+	for i := 0; i < 3; i++ {
+		edk := mocks.NewMockEncryptedDataKey(t)
+		edk.EXPECT().KeyProvider().Return(model.KeyMeta{ProviderID: "raw", KeyID: "key" + strconv.Itoa(i)})
+		edks = append(edks, edk)
+	}
+	mkp.EXPECT().DecryptDataKey(mock.Anything, edks[0], mock.Anything, mock.Anything).
+		Return(nil, providers.ErrMasterKeyProviderDecrypt).Once()
+
+	attempts := providers.NewDecryptAttempts(1)
+	ctx := providers.WithDecryptAttempts(context.Background(), attempts)
+
+	got, err := kp.DecryptDataKeyFromList(ctx, mkp, edks, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, nil)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, providers.ErrMaxDecryptAttempts)
+	assert.ErrorIs(t, err, providers.ErrMasterKeyProviderDecrypt)
+	assert.Equal(t, []model.KeyMeta{
+		{ProviderID: "raw", KeyID: "key1"},
+		{ProviderID: "raw", KeyID: "key2"},
+	}, attempts.Skipped())
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/encryption"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/keyderivation"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/rand"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 type KeyHandler interface {
@@ -57,6 +58,8 @@ type MasterKey struct {
 func NewRawMasterKey(providerID, keyID string, rawKey []byte) (*MasterKey, error) {
 	rawKeyCpy := make([]byte, len(rawKey))
 	copy(rawKeyCpy, rawKey)
+	// only the derived wrapping key is kept
+	defer zeroize.Bytes(rawKeyCpy)
 
 	derivedDataKey, err := keyderivation.DeriveDataEncryptionKey(
 		rawKeyCpy,
@@ -87,6 +90,7 @@ func (rawMK *MasterKey) GenerateDataKey(_ context.Context, alg *suite.AlgorithmS
 
 	encryptedDataKey, err := rawMK.encryptDataKey(dataKey, alg, ec)
 	if err != nil {
+		zeroize.Bytes(dataKey)
 		return nil, fmt.Errorf("RawMasterKey error: %w", errors.Join(keys.ErrGenerateDataKey, err))
 	}
 
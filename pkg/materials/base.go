@@ -9,4 +9,7 @@ import (
 
 var (
 	ErrCMM = errors.New("CMM error")
+
+	// ErrCacheEntryNotFound is returned by BaseCache when there is no usable entry for a cache key.
+	ErrCacheEntryNotFound = errors.New("cache entry not found")
 )
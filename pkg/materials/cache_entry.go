@@ -4,6 +4,7 @@
 package materials
 
 import (
+	"sync"
 	"time"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
@@ -14,8 +15,9 @@ type CacheEntry[V model.EncryptionMaterials | model.DecryptionMaterials] struct
 	value     V
 	createdAt time.Time
 	lifetime  time.Duration
-	messages  uint64 //nolint:unused
-	bytes     int    //nolint:unused
+	mu        sync.Mutex // guards messages, bytes, valid and value key material
+	messages  uint64
+	bytes     int
 	valid     bool
 }
 
@@ -46,11 +48,61 @@ func (ce *CacheEntry[V]) IsTooOld() bool {
 	return ce.Age() > ce.lifetime.Seconds()
 }
 
-func (ce *CacheEntry[V]) updateMeta(b []byte) { //nolint:unused
-	ce.bytes += len(b)
+// Messages returns the number of messages the entry materials were used for.
+func (ce *CacheEntry[V]) Messages() uint64 {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	return ce.messages
+}
+
+// Bytes returns the number of plaintext bytes the entry materials were used for.
+func (ce *CacheEntry[V]) Bytes() int {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	return ce.bytes
+}
+
+// IsValid reports whether the entry can still be used, entry becomes invalid
+// once the caching CMM finds it over its usage limits.
+func (ce *CacheEntry[V]) IsValid() bool {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	return ce.valid
+}
+
+func (ce *CacheEntry[V]) updateMeta(n int) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	ce.bytes += n
 	ce.messages++
 }
 
-func (ce *CacheEntry[V]) invalidate() { //nolint:unused
+func (ce *CacheEntry[V]) invalidate() {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
 	ce.valid = false
 }
+
+// withValue calls fn with the entry value unless the entry is invalid, it
+// reports whether fn was called. Value key material can't be destroyed while
+// fn runs, so fn can safely copy it.
+func (ce *CacheEntry[V]) withValue(fn func(V)) bool {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if !ce.valid {
+		return false
+	}
+	fn(ce.value)
+	return true
+}
+
+// destroy invalidates the entry and wipes its key material, it is called by
+// the cache once the entry is removed.
+func (ce *CacheEntry[V]) destroy() {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	ce.valid = false
+	if m, ok := any(ce.value).(interface{ Destroy() }); ok {
+		m.Destroy()
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"sort"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/conv"
+)
+
+// nullDigest separates encrypted data keys from encryption context in decryption cache ID.
+var nullDigest = make([]byte, sha512.Size) //nolint:gochecknoglobals
+
+// encryptionCacheKey computes cache ID of encryption materials:
+//
+//	SHA512(partition) || SHA512(SHA512(partition) || suite || SHA512(encryption context))
+//
+// where suite is 0x00 when algorithm is unknown, otherwise 0x01 followed by algorithm ID.
+// Cache ID starts with partition digest, so that the cache can find entries of a partition.
+func encryptionCacheKey(partition []byte, algorithm *suite.AlgorithmSuite, ec suite.EncryptionContext) []byte {
+	partitionDigest := digest(partition)
+	h := sha512.New()
+	h.Write(partitionDigest)
+	if algorithm == nil {
+		h.Write([]byte{0x00})
+	} else {
+		h.Write([]byte{0x01})
+		h.Write(algorithm.IDBytes())
+	}
+	h.Write(digest(ec.Serialize()))
+	return h.Sum(partitionDigest)
+}
+
+// decryptionCacheKey computes cache ID of decryption materials:
+//
+//	SHA512(partition) || SHA512(SHA512(partition) || algorithm ID || EDKs digest || 64 zero bytes || SHA512(encryption context))
+//
+// where EDKs digest is concatenation of sorted SHA512 digests of each serialized
+// encrypted data key, so the order of keys in a message does not matter.
+func decryptionCacheKey(partition []byte, algorithm *suite.AlgorithmSuite, edks []model.EncryptedDataKeyI, ec suite.EncryptionContext) []byte {
+	edkDigests := make([][]byte, 0, len(edks))
+	for _, edk := range edks {
+		edkDigests = append(edkDigests, digest(serializeEDK(edk)))
+	}
+	sort.Slice(edkDigests, func(i, j int) bool {
+		return bytes.Compare(edkDigests[i], edkDigests[j]) < 0
+	})
+
+	partitionDigest := digest(partition)
+	h := sha512.New()
+	h.Write(partitionDigest)
+	h.Write(algorithm.IDBytes())
+	for _, d := range edkDigests {
+		h.Write(d)
+	}
+	h.Write(nullDigest)
+	h.Write(digest(ec.Serialize()))
+	return h.Sum(partitionDigest)
+}
+
+// serializeEDK serializes encrypted data key the same way as it is stored in a message header.
+func serializeEDK(edk model.EncryptedDataKeyI) []byte {
+	providerID := edk.KeyProvider().ProviderID
+	keyID := edk.KeyID()
+	encryptedDataKey := edk.EncryptedDataKey()
+
+	buf := make([]byte, 0, 6+len(providerID)+len(keyID)+len(encryptedDataKey)) //nolint:gomnd
+	buf = append(buf, conv.FromInt.Uint16BigEndian(len(providerID))...)
+	buf = append(buf, providerID...)
+	buf = append(buf, conv.FromInt.Uint16BigEndian(len(keyID))...)
+	buf = append(buf, keyID...)
+	buf = append(buf, conv.FromInt.Uint16BigEndian(len(encryptedDataKey))...)
+	buf = append(buf, encryptedDataKey...)
+	return buf
+}
+
+func digest(b []byte) []byte {
+	d := sha512.Sum512(b)
+	return d[:]
+}
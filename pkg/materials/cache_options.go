@@ -0,0 +1,89 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultCacheMaxAge is how long cached materials are used by default.
+	DefaultCacheMaxAge = 5 * time.Minute
+	// MaxCacheMessages is the upper bound of messages encrypted with the same cached materials.
+	MaxCacheMessages = uint64(1 << 32)
+	// MaxCacheBytes is the upper bound of bytes encrypted with the same cached materials.
+	MaxCacheBytes = math.MaxInt64
+)
+
+// CachingOptions are the CachingCryptoMaterialsManager options.
+type CachingOptions struct {
+	partition    string
+	maxAge       time.Duration
+	maxMessages  uint64
+	maxBytes     int
+	cacheSigning bool
+}
+
+// CachingOptionsFunc configures CachingCryptoMaterialsManager.
+type CachingOptionsFunc func(o *CachingOptions) error
+
+// WithPartition sets the partition name which is mixed into every cache ID.
+// CMMs with different partitions never share cache entries, even with the same
+// cache. A random partition is used by default.
+func WithPartition(name string) CachingOptionsFunc {
+	return func(o *CachingOptions) error {
+		if name == "" {
+			return fmt.Errorf("partition name must not be empty")
+		}
+		o.partition = name
+		return nil
+	}
+}
+
+// WithMaxAge sets how long cached materials are used, DefaultCacheMaxAge by default.
+func WithMaxAge(d time.Duration) CachingOptionsFunc {
+	return func(o *CachingOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("max age must be positive")
+		}
+		o.maxAge = d
+		return nil
+	}
+}
+
+// WithMaxMessagesEncrypted sets how many messages are encrypted with the same
+// cached materials, from 1 to MaxCacheMessages. MaxCacheMessages by default.
+func WithMaxMessagesEncrypted(n uint64) CachingOptionsFunc {
+	return func(o *CachingOptions) error {
+		if n < 1 || n > MaxCacheMessages {
+			return fmt.Errorf("max messages encrypted must be between 1 and %d", MaxCacheMessages)
+		}
+		o.maxMessages = n
+		return nil
+	}
+}
+
+// WithMaxBytesEncrypted sets how many plaintext bytes are encrypted with the
+// same cached materials. MaxCacheBytes by default.
+func WithMaxBytesEncrypted(n int) CachingOptionsFunc {
+	return func(o *CachingOptions) error {
+		if n < 0 {
+			return fmt.Errorf("max bytes encrypted must not be negative")
+		}
+		o.maxBytes = n
+		return nil
+	}
+}
+
+// WithSigningSuiteCaching allows caching encryption materials of signing
+// algorithm suites. Messages encrypted with the same cached materials share
+// the signing key, so it is disabled by default.
+func WithSigningSuiteCaching() CachingOptionsFunc {
+	return func(o *CachingOptions) error {
+		o.cacheSigning = true
+		return nil
+	}
+}
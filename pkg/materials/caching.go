@@ -4,49 +4,322 @@
 package materials
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/rand"
 )
 
+const partitionRandomBytes = 16
+
 type BaseCache interface {
+	// PutEncryptionEntry stores encryption materials, the entry is counted as
+	// used once for a message of n plaintext bytes.
 	PutEncryptionEntry(cacheKey []byte, m model.EncryptionMaterials, n int) (*CacheEntry[model.EncryptionMaterials], error)
 	PutDecryptionEntry(cacheKey []byte, m model.DecryptionMaterials) (*CacheEntry[model.DecryptionMaterials], error)
+	// GetEncryptionEntry returns encryption materials entry and counts it as
+	// used for a message of n plaintext bytes. It returns ErrCacheEntryNotFound
+	// when there is no valid entry.
 	GetEncryptionEntry(cacheKey []byte, n int) (*CacheEntry[model.EncryptionMaterials], error)
+	// GetDecryptionEntry returns decryption materials entry, or ErrCacheEntryNotFound
+	// when there is no valid entry.
 	GetDecryptionEntry(cacheKey []byte) (*CacheEntry[model.DecryptionMaterials], error)
 }
 
+// CacheStats are cache usage statistics.
+type CacheStats struct {
+	Hits      uint64 // Hits is the number of lookups which found an entry.
+	Misses    uint64 // Misses is the number of lookups which found no usable entry.
+	Evictions uint64 // Evictions is the number of entries evicted to stay within capacity.
+	Entries   int    // Entries is the current number of entries.
+}
+
+// CacheManager is implemented by caches which support inspection and targeted
+// invalidation, e.g. to flush materials of a compromised or rotated key.
+type CacheManager interface {
+	// Stats returns cache usage statistics.
+	Stats() CacheStats
+	// InvalidateByKeyID removes entries which materials have a data key
+	// encrypted by, or decrypted with, the master key keyID, e.g. KMS key ARN.
+	// It returns the number of removed entries.
+	InvalidateByKeyID(keyID string) int
+	// InvalidateByPartition removes entries cached by CachingCryptoMaterialsManager
+	// with the partition name. It returns the number of removed entries.
+	InvalidateByPartition(partition string) int
+	// Clear removes all entries.
+	Clear()
+}
+
+// CachingCryptoMaterialsManager caches materials of an underlying CMM, so that
+// data keys are reused across messages instead of being generated or decrypted
+// by master key providers for every message.
+//
+// Encryption materials are reused until they reach max age, max messages or
+// max bytes encrypted limit, decryption materials until they reach max age.
+// Materials of algorithm suites without key derivation are never cached, as
+// well as encryption materials for messages of unknown length, e.g. streaming
+// encryption. Encryption materials of signing algorithm suites are cached only
+// with WithSigningSuiteCaching option.
+//
+// Each call returns its own copy of cached key material, so the copy is safely
+// destroyed once the message is done while the cache keeps the entry. Cache
+// owns the entry key material and wipes it once the entry is removed.
 type CachingCryptoMaterialsManager struct {
-	// TODO probably add DefaultCryptoMaterialsManager
-	cache       BaseCache
-	maxAge      time.Duration //nolint:unused
-	maxMessages uint64        //nolint:unused
-	maxBytes    int           //nolint:unused
+	cmm          model.CryptoMaterialsManager
+	cache        BaseCache
+	partition    []byte
+	maxAge       time.Duration
+	maxMessages  uint64
+	maxBytes     int
+	cacheSigning bool
 }
 
-func NewCaching(cache BaseCache, _ model.MasterKeyProvider, _ ...model.MasterKeyProvider) (*CachingCryptoMaterialsManager, error) {
-	_ = &CachingCryptoMaterialsManager{
-		cache: cache,
+// NewCaching returns CachingCryptoMaterialsManager which caches materials of
+// cmm in cache.
+//
+// Parameters:
+//   - cache: BaseCache to store materials in, it can be shared by several CMMs.
+//   - cmm: underlying [model.CryptoMaterialsManager] which materials are cached.
+//   - optFns: CachingOptionsFunc options to set partition and usage limits.
+//
+// Returns:
+//   - *CachingCryptoMaterialsManager: caching CMM.
+//   - error: if cache or cmm is nil, or any option is invalid.
+//
+// Example usage:
+//
+//	cmm, err := materials.NewDefault(kmsKeyProvider)
+//	if err != nil {
+//		panic(err)
+//	}
+//	cachingCMM, err := materials.NewCaching(cache, cmm,
+//		materials.WithMaxAge(10*time.Minute),
+//		materials.WithMaxMessagesEncrypted(1000),
+//	)
+//	if err != nil {
+//		panic(err)
+//	}
+func NewCaching(cache BaseCache, cmm model.CryptoMaterialsManager, optFns ...CachingOptionsFunc) (*CachingCryptoMaterialsManager, error) {
+	if cache == nil {
+		return nil, fmt.Errorf("cache must not be nil: %w", ErrCMM)
+	}
+	if cmm == nil {
+		return nil, fmt.Errorf("underlying CMM must not be nil: %w", ErrCMM)
+	}
+	opts := CachingOptions{
+		maxAge:      DefaultCacheMaxAge,
+		maxMessages: MaxCacheMessages,
+		maxBytes:    MaxCacheBytes,
+	}
+	for _, optFn := range optFns {
+		if err := optFn(&opts); err != nil {
+			return nil, fmt.Errorf("invalid caching option: %w", errors.Join(ErrCMM, err))
+		}
 	}
-	// TODO implement me
-	panic("not implemented yet")
+	if opts.partition == "" {
+		b, err := rand.CryptoRandomBytes(partitionRandomBytes)
+		if err != nil {
+			return nil, fmt.Errorf("partition error: %w", errors.Join(ErrCMM, err))
+		}
+		opts.partition = hex.EncodeToString(b)
+	}
+	return &CachingCryptoMaterialsManager{
+		cmm:          cmm,
+		cache:        cache,
+		partition:    []byte(opts.partition),
+		maxAge:       opts.maxAge,
+		maxMessages:  opts.maxMessages,
+		maxBytes:     opts.maxBytes,
+		cacheSigning: opts.cacheSigning,
+	}, nil
 }
 
 // compile checking that CachingCryptoMaterialsManager implements CryptoMaterialsManager interface
 var _ model.CryptoMaterialsManager = (*CachingCryptoMaterialsManager)(nil)
 
-func (cm *CachingCryptoMaterialsManager) GetEncryptionMaterials(_ context.Context, _ model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
-	//TODO implement me
-	panic("not implemented yet")
+func (cm *CachingCryptoMaterialsManager) GetEncryptionMaterials(ctx context.Context, encReq model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	if !cm.shouldCacheEncryption(encReq) {
+		return cm.cmm.GetEncryptionMaterials(ctx, encReq)
+	}
+
+	cacheKey := encryptionCacheKey(cm.partition, encReq.Algorithm, encReq.EncryptionContext)
+	if entry, err := cm.cache.GetEncryptionEntry(cacheKey, encReq.PlaintextLength); err == nil {
+		var materials model.EncryptionMaterial
+		if cm.isEncryptionEntryUsable(entry) && entry.withValue(func(v model.EncryptionMaterials) {
+			materials = copyEncryptionMaterials(v)
+		}) {
+			return materials, nil
+		}
+		entry.invalidate()
+	}
+
+	// underlying materials are requested for unknown plaintext length,
+	// since they are reused for messages of other lengths
+	materials, err := cm.cmm.GetEncryptionMaterials(ctx, model.EncryptionMaterialsRequest{
+		EncryptionContext: encReq.EncryptionContext,
+		Algorithm:         encReq.Algorithm,
+		PlaintextLength:   -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cached := copyEncryptionMaterials(materials)
+	if _, err := cm.cache.PutEncryptionEntry(cacheKey, *cached, encReq.PlaintextLength); err != nil {
+		// materials are still good for this message
+		cached.Destroy()
+	}
+	return materials, nil
 }
 
-func (cm *CachingCryptoMaterialsManager) DecryptMaterials(_ context.Context, _ model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
-	//TODO implement me
-	panic("not implemented yet")
+func (cm *CachingCryptoMaterialsManager) DecryptMaterials(ctx context.Context, decReq model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	if !decReq.Algorithm.IsKDFSupported() {
+		return cm.cmm.DecryptMaterials(ctx, decReq)
+	}
+
+	cacheKey := decryptionCacheKey(cm.partition, decReq.Algorithm, decReq.EncryptedDataKeys, decReq.EncryptionContext)
+	if entry, err := cm.cache.GetDecryptionEntry(cacheKey); err == nil {
+		var materials model.DecryptionMaterial
+		if !cm.isExpired(entry.Age()) && entry.withValue(func(v model.DecryptionMaterials) {
+			materials = copyDecryptionMaterials(v)
+		}) {
+			return materials, nil
+		}
+		entry.invalidate()
+	}
+
+	materials, err := cm.cmm.DecryptMaterials(ctx, decReq)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := copyDecryptionMaterials(materials)
+	if _, err := cm.cache.PutDecryptionEntry(cacheKey, *cached); err != nil {
+		cached.Destroy()
+	}
+	return materials, nil
 }
 
 func (cm *CachingCryptoMaterialsManager) GetInstance() model.CryptoMaterialsManager {
-	//TODO implement me
-	panic("not implemented yet")
+	return &CachingCryptoMaterialsManager{
+		cmm:          cm.cmm.GetInstance(),
+		cache:        cm.cache,
+		partition:    cm.partition,
+		maxAge:       cm.maxAge,
+		maxMessages:  cm.maxMessages,
+		maxBytes:     cm.maxBytes,
+		cacheSigning: cm.cacheSigning,
+	}
+}
+
+func (cm *CachingCryptoMaterialsManager) shouldCacheEncryption(encReq model.EncryptionMaterialsRequest) bool {
+	if encReq.Algorithm == nil || !encReq.Algorithm.IsKDFSupported() {
+		return false
+	}
+	if encReq.Algorithm.IsSigning() && !cm.cacheSigning {
+		return false
+	}
+	// unknown plaintext length can't be checked against max bytes limit
+	return encReq.PlaintextLength >= 0 && encReq.PlaintextLength <= cm.maxBytes
+}
+
+func (cm *CachingCryptoMaterialsManager) isEncryptionEntryUsable(entry *CacheEntry[model.EncryptionMaterials]) bool {
+	return entry.IsValid() &&
+		!cm.isExpired(entry.Age()) &&
+		entry.Messages() <= cm.maxMessages &&
+		entry.Bytes() <= cm.maxBytes
+}
+
+func (cm *CachingCryptoMaterialsManager) isExpired(age float64) bool {
+	return age > cm.maxAge.Seconds()
+}
+
+// copyEncryptionMaterials copies key material, so that the copy and the original
+// are destroyed independently.
+func copyEncryptionMaterials(m model.EncryptionMaterial) *model.EncryptionMaterials {
+	return model.NewEncryptionMaterials(
+		copyDataKey(m.DataEncryptionKey()),
+		m.EncryptedDataKeys(),
+		m.EncryptionContext(),
+		copySigningKey(m.SigningKey()),
+	).WithRequiredEncryptionContextKeys(requiredEncryptionContextKeys(m))
+}
+
+// copyDecryptionMaterials copies key material, so that the copy and the original
+// are destroyed independently.
+func copyDecryptionMaterials(m model.DecryptionMaterial) *model.DecryptionMaterials {
+	return model.NewDecryptionMaterials(copyDataKey(m.DataKey()), m.VerificationKey()).
+		WithRequiredEncryptionContextKeys(requiredEncryptionContextKeys(m))
+}
+
+func copyDataKey(dk model.DataKeyI) model.DataKeyI {
+	if dk == nil {
+		return nil
+	}
+	return model.NewDataKey(dk.KeyProvider(), bytes.Clone(dk.DataKey()), dk.EncryptedDataKey())
+}
+
+func copySigningKey(key *ecdsa.PrivateKey) *ecdsa.PrivateKey {
+	if key == nil {
+		return nil
+	}
+	return &ecdsa.PrivateKey{PublicKey: key.PublicKey, D: new(big.Int).Set(key.D)}
+}
+
+// CacheStats returns statistics of the cache, it fails if the cache does not
+// implement CacheManager.
+func (cm *CachingCryptoMaterialsManager) CacheStats() (CacheStats, error) {
+	mgr, err := cm.cacheManager()
+	if err != nil {
+		return CacheStats{}, err
+	}
+	return mgr.Stats(), nil
+}
+
+// InvalidateByKeyID removes cached materials of the master key keyID, e.g.
+// after the key is compromised or rotated. It returns the number of removed
+// entries, and fails if the cache does not implement CacheManager.
+func (cm *CachingCryptoMaterialsManager) InvalidateByKeyID(keyID string) (int, error) {
+	mgr, err := cm.cacheManager()
+	if err != nil {
+		return 0, err
+	}
+	return mgr.InvalidateByKeyID(keyID), nil
+}
+
+// InvalidatePartition removes materials cached by this CMM partition, entries of
+// other partitions in a shared cache are kept. It returns the number of removed
+// entries, and fails if the cache does not implement CacheManager.
+func (cm *CachingCryptoMaterialsManager) InvalidatePartition() (int, error) {
+	mgr, err := cm.cacheManager()
+	if err != nil {
+		return 0, err
+	}
+	return mgr.InvalidateByPartition(string(cm.partition)), nil
+}
+
+// ClearCache removes all cache entries, including entries of other partitions
+// in a shared cache. It fails if the cache does not implement CacheManager.
+func (cm *CachingCryptoMaterialsManager) ClearCache() error {
+	mgr, err := cm.cacheManager()
+	if err != nil {
+		return err
+	}
+	mgr.Clear()
+	return nil
+}
+
+func (cm *CachingCryptoMaterialsManager) cacheManager() (CacheManager, error) {
+	mgr, ok := cm.cache.(CacheManager)
+	if !ok {
+		return nil, fmt.Errorf("cache %T does not implement CacheManager: %w", cm.cache, ErrCMM)
+	}
+	return mgr, nil
 }
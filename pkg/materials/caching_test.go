@@ -0,0 +1,258 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/chainifynet/aws-encryption-sdk-go/mocks/github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func newTestCache() *MemoryCache {
+	mc, _ := NewMemoryCache(10, time.Hour)
+	return mc
+}
+
+func newTestEncryptionMaterials(model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	dataKey := model.NewDataKey(model.WithKeyMeta("raw", "key1"), []byte("0123456789abcdef0123456789abcdef"), []byte("encrypted"))
+	edks := []model.EncryptedDataKeyI{model.NewEncryptedDataKey(dataKey.KeyProvider(), dataKey.EncryptedDataKey())}
+	return model.NewEncryptionMaterials(dataKey, edks, suite.EncryptionContext{"a": "b"}, nil), nil
+}
+
+func newTestDecryptionMaterials(model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	dataKey := model.NewDataKey(model.WithKeyMeta("raw", "key1"), []byte("0123456789abcdef0123456789abcdef"), []byte("encrypted"))
+	return model.NewDecryptionMaterials(dataKey, nil), nil
+}
+
+func Test_NewCaching(t *testing.T) {
+	cmm := mocks.NewMockCryptoMaterialsManager(t)
+	tests := []struct {
+		name    string
+		cache   BaseCache
+		cmm     model.CryptoMaterialsManager
+		opts    []CachingOptionsFunc
+		wantErr bool
+	}{
+		{"defaults", newTestCache(), cmm, nil, false},
+		{"all options", newTestCache(), cmm, []CachingOptionsFunc{
+			WithPartition("p1"), WithMaxAge(time.Minute), WithMaxMessagesEncrypted(10), WithMaxBytesEncrypted(1024), WithSigningSuiteCaching(),
+		}, false},
+		{"nil cache", nil, cmm, nil, true},
+		{"nil cmm", newTestCache(), nil, nil, true},
+		{"empty partition", newTestCache(), cmm, []CachingOptionsFunc{WithPartition("")}, true},
+		{"zero max age", newTestCache(), cmm, []CachingOptionsFunc{WithMaxAge(0)}, true},
+		{"zero max messages", newTestCache(), cmm, []CachingOptionsFunc{WithMaxMessagesEncrypted(0)}, true},
+		{"too many max messages", newTestCache(), cmm, []CachingOptionsFunc{WithMaxMessagesEncrypted(MaxCacheMessages + 1)}, true},
+		{"negative max bytes", newTestCache(), cmm, []CachingOptionsFunc{WithMaxBytesEncrypted(-1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCaching(tt.cache, tt.cmm, tt.opts...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCMM)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, got.partition)
+		})
+	}
+}
+
+func TestCachingCryptoMaterialsManager_GetEncryptionMaterials(t *testing.T) {
+	ec := suite.EncryptionContext{"purpose": "test"}
+	tests := []struct {
+		name           string
+		opts           []CachingOptionsFunc
+		algorithm      *suite.AlgorithmSuite
+		ec             []suite.EncryptionContext
+		lengths        []int
+		wantUnderlying int
+	}{
+		{"reused", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec, ec}, []int{10, 10, 10}, 1},
+		{"different context", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, {"purpose": "other"}, ec}, []int{10, 10, 10}, 2},
+		{"max messages", []CachingOptionsFunc{WithMaxMessagesEncrypted(2)}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec, ec, ec}, []int{10, 10, 10, 10}, 2},
+		{"max bytes", []CachingOptionsFunc{WithMaxBytesEncrypted(25)}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec, ec}, []int{10, 10, 10}, 2},
+		{"larger than max bytes", []CachingOptionsFunc{WithMaxBytesEncrypted(5)}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec}, []int{10, 10}, 2},
+		{"max age", []CachingOptionsFunc{WithMaxAge(time.Nanosecond)}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec}, []int{10, 10}, 2},
+		{"unknown length", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, []suite.EncryptionContext{ec, ec}, []int{-1, -1}, 2},
+		{"no kdf", nil, suite.AES_256_GCM_IV12_TAG16_NO_KDF, []suite.EncryptionContext{ec, ec}, []int{10, 10}, 2},
+		{"signing", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, []suite.EncryptionContext{ec, ec}, []int{10, 10}, 2},
+		{"signing allowed", []CachingOptionsFunc{WithSigningSuiteCaching()}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, []suite.EncryptionContext{ec, ec}, []int{10, 10}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := mocks.NewMockCryptoMaterialsManager(t)
+			underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, req model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+					return newTestEncryptionMaterials(req)
+				}).Times(tt.wantUnderlying)
+
+			cm, err := NewCaching(newTestCache(), underlying, tt.opts...)
+			assert.NoError(t, err)
+
+			for i := range tt.ec {
+				got, err := cm.GetEncryptionMaterials(context.Background(), model.EncryptionMaterialsRequest{
+					EncryptionContext: tt.ec[i],
+					Algorithm:         tt.algorithm,
+					PlaintextLength:   tt.lengths[i],
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), got.DataEncryptionKey().DataKey())
+				// materials are destroyed after each message, cached copy must survive
+				got.(model.Destroyer).Destroy()
+			}
+		})
+	}
+}
+
+func TestCachingCryptoMaterialsManager_GetEncryptionMaterials_Error(t *testing.T) {
+	underlying := mocks.NewMockCryptoMaterialsManager(t)
+	underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("no keys: %w", ErrCMM)).Once()
+
+	cm, err := NewCaching(newTestCache(), underlying)
+	assert.NoError(t, err)
+
+	got, err := cm.GetEncryptionMaterials(context.Background(), model.EncryptionMaterialsRequest{
+		Algorithm:       suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		PlaintextLength: 10,
+	})
+	assert.ErrorIs(t, err, ErrCMM)
+	assert.Nil(t, got)
+}
+
+func TestCachingCryptoMaterialsManager_DecryptMaterials(t *testing.T) {
+	edk1 := model.NewEncryptedDataKey(model.WithKeyMeta("raw", "key1"), []byte("edk1"))
+	edk2 := model.NewEncryptedDataKey(model.WithKeyMeta("raw", "key2"), []byte("edk2"))
+	ec := suite.EncryptionContext{"purpose": "test"}
+	tests := []struct {
+		name           string
+		opts           []CachingOptionsFunc
+		algorithm      *suite.AlgorithmSuite
+		edks           [][]model.EncryptedDataKeyI
+		wantUnderlying int
+	}{
+		{"reused", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, [][]model.EncryptedDataKeyI{{edk1, edk2}, {edk1, edk2}}, 1},
+		{"edk order", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, [][]model.EncryptedDataKeyI{{edk1, edk2}, {edk2, edk1}}, 1},
+		{"different edks", nil, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, [][]model.EncryptedDataKeyI{{edk1, edk2}, {edk1}}, 2},
+		{"max age", []CachingOptionsFunc{WithMaxAge(time.Nanosecond)}, suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, [][]model.EncryptedDataKeyI{{edk1}, {edk1}}, 2},
+		{"no kdf", nil, suite.AES_256_GCM_IV12_TAG16_NO_KDF, [][]model.EncryptedDataKeyI{{edk1}, {edk1}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := mocks.NewMockCryptoMaterialsManager(t)
+			underlying.EXPECT().DecryptMaterials(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, req model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+					return newTestDecryptionMaterials(req)
+				}).Times(tt.wantUnderlying)
+
+			cm, err := NewCaching(newTestCache(), underlying, tt.opts...)
+			assert.NoError(t, err)
+
+			for _, edks := range tt.edks {
+				got, err := cm.DecryptMaterials(context.Background(), model.DecryptionMaterialsRequest{
+					Algorithm:         tt.algorithm,
+					EncryptedDataKeys: edks,
+					EncryptionContext: ec,
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), got.DataKey().DataKey())
+				got.(model.Destroyer).Destroy()
+			}
+		})
+	}
+}
+
+func TestCachingCryptoMaterialsManager_Partition(t *testing.T) {
+	cache := newTestCache()
+	req := model.EncryptionMaterialsRequest{
+		Algorithm:       suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		PlaintextLength: 10,
+	}
+
+	underlying := mocks.NewMockCryptoMaterialsManager(t)
+	underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, req model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+			return newTestEncryptionMaterials(req)
+		}).Twice()
+
+	cm1, err := NewCaching(cache, underlying, WithPartition("p1"))
+	assert.NoError(t, err)
+	cm2, err := NewCaching(cache, underlying, WithPartition("p2"))
+	assert.NoError(t, err)
+	cm3, err := NewCaching(cache, underlying, WithPartition("p1"))
+	assert.NoError(t, err)
+
+	for _, cm := range []*CachingCryptoMaterialsManager{cm1, cm2, cm3} {
+		_, err = cm.GetEncryptionMaterials(context.Background(), req)
+		assert.NoError(t, err)
+	}
+}
+
+type unmanagedCache struct {
+	BaseCache
+}
+
+func TestCachingCryptoMaterialsManager_CacheManager(t *testing.T) {
+	underlying := mocks.NewMockCryptoMaterialsManager(t)
+	underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, req model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+			return newTestEncryptionMaterials(req)
+		}).Times(3)
+
+	cache := newTestCache()
+	cm1, err := NewCaching(cache, underlying, WithPartition("p1"))
+	require.NoError(t, err)
+	cm2, err := NewCaching(cache, underlying, WithPartition("p2"))
+	require.NoError(t, err)
+
+	req := model.EncryptionMaterialsRequest{
+		Algorithm:       suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		PlaintextLength: 10,
+	}
+	for _, cm := range []*CachingCryptoMaterialsManager{cm1, cm2, cm1} {
+		_, err = cm.GetEncryptionMaterials(context.Background(), req)
+		require.NoError(t, err)
+	}
+
+	stats, err := cm1.CacheStats()
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Entries: 2}, stats)
+
+	n, err := cm1.InvalidatePartition()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = cm2.InvalidateByKeyID("key1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// cache is empty, so materials are requested again
+	_, err = cm1.GetEncryptionMaterials(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, cm1.ClearCache())
+	stats, err = cm2.CacheStats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Entries)
+
+	unmanaged, err := NewCaching(unmanagedCache{cache}, underlying)
+	require.NoError(t, err)
+	_, err = unmanaged.CacheStats()
+	assert.ErrorIs(t, err, ErrCMM)
+	_, err = unmanaged.InvalidateByKeyID("key1")
+	assert.ErrorIs(t, err, ErrCMM)
+	_, err = unmanaged.InvalidatePartition()
+	assert.ErrorIs(t, err, ErrCMM)
+	assert.ErrorIs(t, unmanaged.ClearCache(), ErrCMM)
+}
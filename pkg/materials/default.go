@@ -17,6 +17,7 @@ import (
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/providers"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/rand"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 type DefaultCryptoMaterialsManager struct {
@@ -61,6 +62,14 @@ func (dm *DefaultCryptoMaterialsManager) GetEncryptionMaterials(ctx context.Cont
 		return nil, fmt.Errorf("signing key update: %w", errors.Join(ErrCMM, err))
 	}
 
+	// signing key is wiped on any error below, materials own it otherwise
+	materialsReady := false
+	defer func() {
+		if !materialsReady {
+			zeroize.ECDSAPrivateKey(signingKey)
+		}
+	}()
+
 	encryptionContext = structs.MapSort(encryptionContext)
 
 	var masterKeys []model.MasterKey
@@ -88,6 +97,7 @@ func (dm *DefaultCryptoMaterialsManager) GetEncryptionMaterials(ctx context.Cont
 	if err != nil {
 		return nil, fmt.Errorf("key error: %w", errors.Join(ErrCMM, err))
 	}
+	materialsReady = true
 	return model.NewEncryptionMaterials(dataEncryptionKey, encryptedDataKeys, encryptionContext, signingKey), nil
 
 }
@@ -95,6 +105,11 @@ func (dm *DefaultCryptoMaterialsManager) GetEncryptionMaterials(ctx context.Cont
 func (dm *DefaultCryptoMaterialsManager) DecryptMaterials(ctx context.Context, decReq model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
 	var dataKey model.DataKeyI
 	var errDecryptDataKey error
+	attempts := providers.DecryptAttemptsFromContext(ctx)
+	if attempts == nil && decReq.MaxDecryptAttempts > 0 {
+		attempts = providers.NewDecryptAttempts(decReq.MaxDecryptAttempts)
+		ctx = providers.WithDecryptAttempts(ctx, attempts)
+	}
 	dataKeyPrimary, err := dm.primaryKeyProvider.DecryptDataKeyFromList(ctx, decReq.EncryptedDataKeys, decReq.Algorithm, decReq.EncryptionContext)
 	if err != nil {
 		if !errors.Is(err, providers.ErrMasterKeyProviderDecrypt) {
@@ -122,6 +137,9 @@ func (dm *DefaultCryptoMaterialsManager) DecryptMaterials(ctx context.Context, d
 	}
 
 	if dataKey == nil {
+		if skipped := attempts.Skipped(); len(skipped) > 0 {
+			return nil, fmt.Errorf("no data key, %d decrypt attempts skipped %v: %w", len(skipped), skipped, errors.Join(ErrCMM, providers.ErrMaxDecryptAttempts, errDecryptDataKey))
+		}
 		if errDecryptDataKey != nil {
 			return nil, fmt.Errorf("no data key, last error: %w", errors.Join(ErrCMM, errDecryptDataKey))
 		}
@@ -136,11 +154,13 @@ func (dm *DefaultCryptoMaterialsManager) DecryptMaterials(ctx context.Context, d
 
 	// handle signing algo
 	if _, ok := decReq.EncryptionContext[encryptedContextAWSKey]; !ok {
+		zeroize.Bytes(dataKey.DataKey())
 		return nil, fmt.Errorf("missing %s in encryption context: %w", encryptedContextAWSKey, errors.Join(ErrCMM, err))
 	}
 	pubKeyStr := decReq.EncryptionContext[encryptedContextAWSKey]
 	verificationKey, err := b64.StdEncoding.DecodeString(pubKeyStr)
 	if err != nil {
+		zeroize.Bytes(dataKey.DataKey())
 		return nil, fmt.Errorf("ECDSA key error: %w", errors.Join(ErrCMM, err))
 	}
 
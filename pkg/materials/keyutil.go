@@ -8,6 +8,7 @@ import (
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 // TODO andrew refactor, for sure it needs to be moved under keys or providers likely package
@@ -30,6 +31,7 @@ func prepareDataKeys(ctx context.Context, primaryMasterKey model.MasterKey, mast
 		}
 		encryptedKey, err := masterKey.EncryptDataKey(ctx, dataEncryptionKey, algorithm, ec)
 		if err != nil {
+			zeroize.Bytes(dataEncryptionKey.DataKey())
 			// TODO just wrap err
 			return nil, nil, err
 		}
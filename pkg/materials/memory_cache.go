@@ -4,50 +4,229 @@
 package materials
 
 import (
+	"container/list"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
 )
 
+// MemoryCache is an in-memory BaseCache with a maximum number of entries.
+//
+// When the cache is full, the least recently used entry is evicted. Entries
+// older than the cache entry lifetime, as well as entries invalidated by the
+// caching CMM, are dropped on access. Key material of removed entries is wiped.
+//
+// MemoryCache is safe for concurrent use by multiple goroutines.
 type MemoryCache struct {
-	cache sync.Map //nolint:unused
-}
-
-// compile checking that MemoryCache implements BaseCache interface
-var _ BaseCache = (*MemoryCache)(nil)
-
-func (mc *MemoryCache) PutEncryptionEntry(_ []byte, _ model.EncryptionMaterials, _ int) (*CacheEntry[model.EncryptionMaterials], error) {
-	//entry := NewCacheEntry(key, em, 300) //nolint:gomnd
-	//mc.cache.Store(key, entry)
-	//return entry, nil
-	// TODO implement me
-	panic("not implemented yet")
-}
-
-func (mc *MemoryCache) PutDecryptionEntry(_ []byte, _ model.DecryptionMaterials) (*CacheEntry[model.DecryptionMaterials], error) {
-	//entry := NewCacheEntry(key, dm, 300) //nolint:gomnd
-	//mc.cache.Store(key, entry)
-	//return entry, nil
-	// TODO implement me
-	panic("not implemented yet")
-}
-
-func (mc *MemoryCache) GetEncryptionEntry(_ []byte, _ int) (*CacheEntry[model.EncryptionMaterials], error) {
-	//entry, ok := mc.cache.Load(key)
-	//if !ok {
-	//	return nil, fmt.Errorf("cache entry not found")
-	//}
-	//return entry.(*CacheEntry[EncryptionMaterials]), nil
-	// TODO implement me
-	panic("not implemented yet")
-}
-
-func (mc *MemoryCache) GetDecryptionEntry(_ []byte) (*CacheEntry[model.DecryptionMaterials], error) {
-	//entry, ok := mc.cache.Load(key)
-	//if !ok {
-	//	return nil, fmt.Errorf("cache entry not found")
-	//}
-	//return entry.(*CacheEntry[DecryptionMaterials]), nil
-	// TODO implement me
-	panic("not implemented yet")
+	mu       sync.Mutex
+	capacity int
+	lifetime time.Duration
+	entries  map[string]*list.Element
+	lru      *list.List // most recently used entries at the front
+	stats    CacheStats
+}
+
+type memoryCacheItem struct {
+	key string
+	enc *CacheEntry[model.EncryptionMaterials]
+	dec *CacheEntry[model.DecryptionMaterials]
+}
+
+// compile checking that MemoryCache implements BaseCache and CacheManager interfaces
+var (
+	_ BaseCache    = (*MemoryCache)(nil)
+	_ CacheManager = (*MemoryCache)(nil)
+)
+
+// NewMemoryCache returns MemoryCache which keeps up to capacity entries, each
+// of them for up to lifetime.
+//
+// Parameters:
+//   - capacity: maximum number of entries, must be positive.
+//   - lifetime: maximum age of an entry, must be positive.
+//
+// Returns:
+//   - *MemoryCache: in-memory cache.
+//   - error: if capacity or lifetime is not positive.
+//
+// Example usage:
+//
+//	cache, err := materials.NewMemoryCache(100, 10*time.Minute)
+//	if err != nil {
+//		panic(err)
+//	}
+//	cachingCMM, err := materials.NewCaching(cache, cmm)
+func NewMemoryCache(capacity int, lifetime time.Duration) (*MemoryCache, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("cache capacity must be positive: %w", ErrCMM)
+	}
+	if lifetime <= 0 {
+		return nil, fmt.Errorf("cache entry lifetime must be positive: %w", ErrCMM)
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		lifetime: lifetime,
+		entries:  make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+	}, nil
+}
+
+func (mc *MemoryCache) PutEncryptionEntry(cacheKey []byte, m model.EncryptionMaterials, n int) (*CacheEntry[model.EncryptionMaterials], error) {
+	entry := NewCacheEntry(cacheKey, m, mc.lifetime)
+	entry.updateMeta(n)
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.put(&memoryCacheItem{key: string(cacheKey), enc: entry})
+	return entry, nil
+}
+
+func (mc *MemoryCache) PutDecryptionEntry(cacheKey []byte, m model.DecryptionMaterials) (*CacheEntry[model.DecryptionMaterials], error) {
+	entry := NewCacheEntry(cacheKey, m, mc.lifetime)
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.put(&memoryCacheItem{key: string(cacheKey), dec: entry})
+	return entry, nil
+}
+
+func (mc *MemoryCache) GetEncryptionEntry(cacheKey []byte, n int) (*CacheEntry[model.EncryptionMaterials], error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	item := mc.get(string(cacheKey), true)
+	if item == nil {
+		return nil, ErrCacheEntryNotFound
+	}
+	item.enc.updateMeta(n)
+	return item.enc, nil
+}
+
+func (mc *MemoryCache) GetDecryptionEntry(cacheKey []byte) (*CacheEntry[model.DecryptionMaterials], error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	item := mc.get(string(cacheKey), false)
+	if item == nil {
+		return nil, ErrCacheEntryNotFound
+	}
+	return item.dec, nil
+}
+
+// put stores item replacing an entry with the same key, and evicts least
+// recently used entries over capacity. mc.mu must be held.
+func (mc *MemoryCache) put(item *memoryCacheItem) {
+	if el, ok := mc.entries[item.key]; ok {
+		mc.remove(el)
+	}
+	mc.entries[item.key] = mc.lru.PushFront(item)
+	for mc.lru.Len() > mc.capacity {
+		mc.remove(mc.lru.Back())
+		mc.stats.Evictions++
+	}
+}
+
+// get returns a usable item of encryption or decryption materials and marks it
+// as most recently used, an invalid or too old item is removed. mc.mu must be held.
+func (mc *MemoryCache) get(key string, encryption bool) *memoryCacheItem {
+	el, ok := mc.entries[key]
+	if !ok {
+		mc.stats.Misses++
+		return nil
+	}
+	item := el.Value.(*memoryCacheItem) //nolint:forcetypeassert
+	if (item.enc != nil) != encryption {
+		mc.stats.Misses++
+		return nil
+	}
+	if !item.isUsable() {
+		mc.remove(el)
+		mc.stats.Misses++
+		return nil
+	}
+	mc.lru.MoveToFront(el)
+	mc.stats.Hits++
+	return item
+}
+
+// remove drops the element and wipes its key material. mc.mu must be held.
+func (mc *MemoryCache) remove(el *list.Element) {
+	item := mc.lru.Remove(el).(*memoryCacheItem) //nolint:forcetypeassert
+	delete(mc.entries, item.key)
+	item.destroy()
+}
+
+// Stats returns cache usage statistics.
+func (mc *MemoryCache) Stats() CacheStats {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	stats := mc.stats
+	stats.Entries = mc.lru.Len()
+	return stats
+}
+
+// InvalidateByKeyID removes entries which materials have a data key encrypted
+// by, or decrypted with, the master key keyID.
+func (mc *MemoryCache) InvalidateByKeyID(keyID string) int {
+	return mc.removeIf(func(item *memoryCacheItem) bool {
+		return item.hasKeyID(keyID)
+	})
+}
+
+// InvalidateByPartition removes entries cached by CachingCryptoMaterialsManager
+// with the partition name.
+func (mc *MemoryCache) InvalidateByPartition(partition string) int {
+	prefix := string(digest([]byte(partition)))
+	return mc.removeIf(func(item *memoryCacheItem) bool {
+		return strings.HasPrefix(item.key, prefix)
+	})
+}
+
+// Clear removes all entries.
+func (mc *MemoryCache) Clear() {
+	mc.removeIf(func(*memoryCacheItem) bool { return true })
+}
+
+func (mc *MemoryCache) removeIf(fn func(item *memoryCacheItem) bool) int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	removed := 0
+	for el := mc.lru.Front(); el != nil; {
+		next := el.Next()
+		if fn(el.Value.(*memoryCacheItem)) { //nolint:forcetypeassert
+			mc.remove(el)
+			removed++
+		}
+		el = next
+	}
+	return removed
+}
+
+func (i *memoryCacheItem) isUsable() bool {
+	if i.enc != nil {
+		return i.enc.IsValid() && !i.enc.IsTooOld()
+	}
+	return i.dec.IsValid() && !i.dec.IsTooOld()
+}
+
+func (i *memoryCacheItem) destroy() {
+	if i.enc != nil {
+		i.enc.destroy()
+		return
+	}
+	i.dec.destroy()
+}
+
+func (i *memoryCacheItem) hasKeyID(keyID string) bool {
+	if i.enc == nil {
+		dataKey := i.dec.Value().DataKey()
+		return dataKey != nil && dataKey.KeyID() == keyID
+	}
+	for _, edk := range i.enc.Value().EncryptedDataKeys() {
+		if edk.KeyID() == keyID {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,214 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+)
+
+func newTestCacheMaterials() (model.EncryptionMaterials, model.DecryptionMaterials) {
+	em, _ := newTestEncryptionMaterials(model.EncryptionMaterialsRequest{})
+	dm, _ := newTestDecryptionMaterials(model.DecryptionMaterialsRequest{})
+	return *copyEncryptionMaterials(em), *copyDecryptionMaterials(dm)
+}
+
+func Test_NewMemoryCache(t *testing.T) {
+	_, err := NewMemoryCache(0, time.Minute)
+	assert.ErrorIs(t, err, ErrCMM)
+	_, err = NewMemoryCache(1, 0)
+	assert.ErrorIs(t, err, ErrCMM)
+	mc, err := NewMemoryCache(1, time.Minute)
+	assert.NoError(t, err)
+	assert.NotNil(t, mc)
+}
+
+func TestMemoryCache_PutGet(t *testing.T) {
+	mc, err := NewMemoryCache(10, time.Minute)
+	require.NoError(t, err)
+	em, dm := newTestCacheMaterials()
+
+	_, err = mc.GetEncryptionEntry([]byte("enc"), 10)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.GetDecryptionEntry([]byte("dec"))
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+
+	put, err := mc.PutEncryptionEntry([]byte("enc"), em, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), put.Messages())
+	assert.Equal(t, 10, put.Bytes())
+
+	got, err := mc.GetEncryptionEntry([]byte("enc"), 5)
+	require.NoError(t, err)
+	assert.Same(t, put, got)
+	assert.Equal(t, uint64(2), got.Messages())
+	assert.Equal(t, 15, got.Bytes())
+
+	_, err = mc.PutDecryptionEntry([]byte("dec"), dm)
+	require.NoError(t, err)
+	_, err = mc.GetDecryptionEntry([]byte("dec"))
+	assert.NoError(t, err)
+
+	// keys of other materials type are not found
+	_, err = mc.GetDecryptionEntry([]byte("enc"))
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.GetEncryptionEntry([]byte("dec"), 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+}
+
+func TestMemoryCache_LRU(t *testing.T) {
+	mc, err := NewMemoryCache(2, time.Minute)
+	require.NoError(t, err)
+	em, _ := newTestCacheMaterials()
+
+	first, err := mc.PutEncryptionEntry([]byte("1"), em, 0)
+	require.NoError(t, err)
+	_, err = mc.PutEncryptionEntry([]byte("2"), *copyEncryptionMaterials(em), 0)
+	require.NoError(t, err)
+	// "1" becomes most recently used, so "2" is evicted
+	_, err = mc.GetEncryptionEntry([]byte("1"), 0)
+	require.NoError(t, err)
+	_, err = mc.PutEncryptionEntry([]byte("3"), *copyEncryptionMaterials(em), 0)
+	require.NoError(t, err)
+
+	_, err = mc.GetEncryptionEntry([]byte("2"), 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.GetEncryptionEntry([]byte("1"), 0)
+	assert.NoError(t, err)
+	_, err = mc.GetEncryptionEntry([]byte("3"), 0)
+	assert.NoError(t, err)
+
+	// replaced entry is removed and wiped
+	_, err = mc.PutEncryptionEntry([]byte("1"), *copyEncryptionMaterials(em), 0)
+	require.NoError(t, err)
+	assert.False(t, first.IsValid())
+	assert.Equal(t, make([]byte, 32), first.Value().DataEncryptionKey().DataKey())
+}
+
+func TestMemoryCache_Expired(t *testing.T) {
+	mc, err := NewMemoryCache(10, time.Nanosecond)
+	require.NoError(t, err)
+	em, dm := newTestCacheMaterials()
+
+	put, err := mc.PutEncryptionEntry([]byte("enc"), em, 0)
+	require.NoError(t, err)
+	_, err = mc.PutDecryptionEntry([]byte("dec"), dm)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	_, err = mc.GetEncryptionEntry([]byte("enc"), 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.GetDecryptionEntry([]byte("dec"))
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	assert.Equal(t, make([]byte, 32), put.Value().DataEncryptionKey().DataKey())
+}
+
+func TestMemoryCache_Invalidated(t *testing.T) {
+	mc, err := NewMemoryCache(10, time.Minute)
+	require.NoError(t, err)
+	_, dm := newTestCacheMaterials()
+
+	put, err := mc.PutDecryptionEntry([]byte("dec"), dm)
+	require.NoError(t, err)
+	put.invalidate()
+
+	_, err = mc.GetDecryptionEntry([]byte("dec"))
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	assert.Equal(t, make([]byte, 32), put.Value().DataKey().DataKey())
+}
+
+func TestMemoryCache_Concurrent(t *testing.T) {
+	mc, err := NewMemoryCache(4, time.Minute)
+	require.NoError(t, err)
+	em, _ := newTestCacheMaterials()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := []byte(fmt.Sprintf("%d", (g+i)%8))
+				if entry, err := mc.GetEncryptionEntry(key, 1); err == nil {
+					entry.withValue(func(v model.EncryptionMaterials) {
+						copyEncryptionMaterials(v).Destroy()
+					})
+					continue
+				}
+				_, _ = mc.PutEncryptionEntry(key, *copyEncryptionMaterials(em), 1)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	assert.LessOrEqual(t, mc.lru.Len(), 4)
+	assert.Equal(t, mc.lru.Len(), len(mc.entries))
+}
+
+func TestMemoryCache_Stats(t *testing.T) {
+	mc, err := NewMemoryCache(1, time.Minute)
+	require.NoError(t, err)
+	em, _ := newTestCacheMaterials()
+
+	_, err = mc.GetEncryptionEntry([]byte("1"), 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.PutEncryptionEntry([]byte("1"), em, 0)
+	require.NoError(t, err)
+	_, err = mc.GetEncryptionEntry([]byte("1"), 0)
+	require.NoError(t, err)
+	_, err = mc.PutEncryptionEntry([]byte("2"), *copyEncryptionMaterials(em), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1}, mc.Stats())
+
+	mc.Clear()
+	assert.Equal(t, 0, mc.Stats().Entries)
+	_, err = mc.GetEncryptionEntry([]byte("2"), 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+}
+
+func TestMemoryCache_InvalidateByKeyID(t *testing.T) {
+	mc, err := NewMemoryCache(10, time.Minute)
+	require.NoError(t, err)
+	em, dm := newTestCacheMaterials()
+
+	enc, err := mc.PutEncryptionEntry([]byte("enc"), em, 0)
+	require.NoError(t, err)
+	dec, err := mc.PutDecryptionEntry([]byte("dec"), dm)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, mc.InvalidateByKeyID("unknown"))
+	assert.Equal(t, 2, mc.InvalidateByKeyID("key1"))
+	assert.False(t, enc.IsValid())
+	assert.False(t, dec.IsValid())
+	assert.Equal(t, 0, mc.Stats().Entries)
+}
+
+func TestMemoryCache_InvalidateByPartition(t *testing.T) {
+	mc, err := NewMemoryCache(10, time.Minute)
+	require.NoError(t, err)
+	em, _ := newTestCacheMaterials()
+
+	p1 := encryptionCacheKey([]byte("p1"), nil, nil)
+	p2 := encryptionCacheKey([]byte("p2"), nil, nil)
+	_, err = mc.PutEncryptionEntry(p1, em, 0)
+	require.NoError(t, err)
+	_, err = mc.PutEncryptionEntry(p2, *copyEncryptionMaterials(em), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, mc.InvalidateByPartition("p1"))
+	_, err = mc.GetEncryptionEntry(p1, 0)
+	assert.ErrorIs(t, err, ErrCacheEntryNotFound)
+	_, err = mc.GetEncryptionEntry(p2, 0)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,352 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+const (
+	// poolRefillRetryDelay is how long a pool is not refilled after a refill error.
+	poolRefillRetryDelay = time.Second
+	// poolMinSweepInterval is the lower bound of expired keys sweep interval.
+	poolMinSweepInterval = time.Millisecond
+)
+
+// PoolingCryptoMaterialsManager generates encryption materials of an underlying
+// CMM ahead of time, so that master key GenerateDataKey and EncryptDataKey
+// latency is off the encryption path.
+//
+// Materials are pooled per algorithm suite and encryption context shape. A pool
+// is created on the first request of its shape, which gets materials from the
+// underlying CMM directly, and refilled in background after each request. Every
+// pooled data key is used by one message only, unlike CachingCryptoMaterialsManager.
+// Keys older than max key age are discarded and wiped, also in background
+// every half of max key age, so keys of idle pools do not outlive max key age
+// for long. When a pool is empty, materials are requested from the underlying
+// CMM directly, so refill errors are returned to the caller that way.
+// Decryption materials are never pooled.
+//
+// Pools idle for longer than max key age are removed once their keys expire.
+// At most max pools are kept, the least recently used pool is evicted when
+// a new shape is requested. Encryption context with values unique per message,
+// e.g. request ID, gains nothing from pooling.
+//
+// Close stops background refill and sweep, and wipes pooled key material.
+type PoolingCryptoMaterialsManager struct {
+	cmm       model.CryptoMaterialsManager
+	size      int
+	maxKeyAge time.Duration
+	maxPools  int
+	refillSem chan struct{} // refillSem limits concurrent background refills
+
+	ctx    context.Context // ctx is canceled by Close
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	pools  map[string]*materialsPool
+	closed bool
+}
+
+// materialsPool is a FIFO of pooled materials of one shape.
+type materialsPool struct {
+	request  model.EncryptionMaterialsRequest
+	items    []pooledMaterials
+	pending  int       // pending is the number of refills in flight
+	failedAt time.Time // failedAt is the time of the last refill error
+	usedAt   time.Time // usedAt is the time of the last take from the pool
+	evicted  bool      // evicted pool is no longer in the pools map
+}
+
+type pooledMaterials struct {
+	materials model.EncryptionMaterial
+	createdAt time.Time
+}
+
+// compile checking that PoolingCryptoMaterialsManager implements CryptoMaterialsManager interface
+var _ model.CryptoMaterialsManager = (*PoolingCryptoMaterialsManager)(nil)
+
+// NewPooling returns PoolingCryptoMaterialsManager which pre-generates
+// encryption materials of cmm.
+//
+// Parameters:
+//   - cmm: underlying [model.CryptoMaterialsManager] which generates materials.
+//   - optFns: PoolOptionsFunc options to set pool size, refill concurrency, max key age and max pools.
+//
+// Returns:
+//   - *PoolingCryptoMaterialsManager: pooling CMM, it must be closed once not used.
+//   - error: if cmm is nil, or any option is invalid.
+//
+// Example usage:
+//
+//	cmm, err := materials.NewDefault(kmsKeyProvider)
+//	if err != nil {
+//		panic(err)
+//	}
+//	poolingCMM, err := materials.NewPooling(cmm,
+//		materials.WithPoolSize(16),
+//		materials.WithPoolMaxKeyAge(time.Minute),
+//	)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer poolingCMM.Close()
+func NewPooling(cmm model.CryptoMaterialsManager, optFns ...PoolOptionsFunc) (*PoolingCryptoMaterialsManager, error) {
+	if cmm == nil {
+		return nil, fmt.Errorf("underlying CMM must not be nil: %w", ErrCMM)
+	}
+	opts := PoolOptions{
+		size:              DefaultPoolSize,
+		refillConcurrency: DefaultPoolRefillConcurrency,
+		maxKeyAge:         DefaultPoolMaxKeyAge,
+		maxPools:          DefaultPoolMaxPools,
+	}
+	for _, optFn := range optFns {
+		if err := optFn(&opts); err != nil {
+			return nil, fmt.Errorf("invalid pool option: %w", errors.Join(ErrCMM, err))
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pm := &PoolingCryptoMaterialsManager{
+		cmm:       cmm,
+		size:      opts.size,
+		maxKeyAge: opts.maxKeyAge,
+		maxPools:  opts.maxPools,
+		refillSem: make(chan struct{}, opts.refillConcurrency),
+		ctx:       ctx,
+		cancel:    cancel,
+		pools:     make(map[string]*materialsPool),
+	}
+	pm.wg.Add(1)
+	go pm.sweepLoop()
+	return pm, nil
+}
+
+func (pm *PoolingCryptoMaterialsManager) GetEncryptionMaterials(ctx context.Context, encReq model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	if materials, err := pm.take(encReq); err != nil || materials != nil {
+		return materials, err
+	}
+	return pm.cmm.GetEncryptionMaterials(ctx, encReq)
+}
+
+func (pm *PoolingCryptoMaterialsManager) DecryptMaterials(ctx context.Context, decReq model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	return pm.cmm.DecryptMaterials(ctx, decReq)
+}
+
+// GetInstance returns pm itself, pools are shared by all messages.
+func (pm *PoolingCryptoMaterialsManager) GetInstance() model.CryptoMaterialsManager {
+	return pm
+}
+
+// Close stops background refill and sweep, waits for in-flight refills and wipes key
+// material of pooled data keys. Materials already returned are not affected.
+func (pm *PoolingCryptoMaterialsManager) Close() error {
+	pm.mu.Lock()
+	if pm.closed {
+		pm.mu.Unlock()
+		return nil
+	}
+	pm.closed = true
+	pm.cancel()
+	pm.mu.Unlock()
+
+	pm.wg.Wait()
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for _, pool := range pm.pools {
+		for _, item := range pool.items {
+			destroyMaterials(item.materials)
+		}
+		pool.items = nil
+	}
+	return nil
+}
+
+// take pops the oldest usable pooled materials of encReq shape and schedules
+// refill of the pool. It returns nil materials when the pool is empty.
+func (pm *PoolingCryptoMaterialsManager) take(encReq model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.closed {
+		return nil, fmt.Errorf("pool is closed: %w", ErrCMM)
+	}
+
+	key := string(encryptionCacheKey(nil, encReq.Algorithm, encReq.EncryptionContext))
+	pool, ok := pm.pools[key]
+	if !ok {
+		if len(pm.pools) >= pm.maxPools {
+			pm.evictLeastRecentlyUsed()
+		}
+		pool = &materialsPool{request: model.EncryptionMaterialsRequest{
+			EncryptionContext: copyEncryptionContext(encReq.EncryptionContext),
+			Algorithm:         encReq.Algorithm,
+			PlaintextLength:   -1,
+		}}
+		pm.pools[key] = pool
+	}
+	pool.usedAt = time.Now()
+
+	var materials model.EncryptionMaterial
+	for len(pool.items) > 0 && materials == nil {
+		item := pool.items[0]
+		pool.items[0] = pooledMaterials{}
+		pool.items = pool.items[1:]
+		if time.Since(item.createdAt) > pm.maxKeyAge {
+			destroyMaterials(item.materials)
+			continue
+		}
+		materials = item.materials
+	}
+
+	pm.refill(pool)
+	return materials, nil
+}
+
+// refill starts background generation of materials missing from the pool,
+// unless the last refill failed recently. pm.mu must be held.
+func (pm *PoolingCryptoMaterialsManager) refill(pool *materialsPool) {
+	if time.Since(pool.failedAt) < poolRefillRetryDelay {
+		return
+	}
+	for ; len(pool.items)+pool.pending < pm.size; pool.pending++ {
+		pm.wg.Add(1)
+		go pm.generate(pool)
+	}
+}
+
+// generate adds one materials of pool shape to the pool. Refill errors are not
+// returned, the next request on empty pool falls back to the underlying CMM.
+func (pm *PoolingCryptoMaterialsManager) generate(pool *materialsPool) {
+	defer pm.wg.Done()
+
+	var materials model.EncryptionMaterial
+	var err error
+	select {
+	case pm.refillSem <- struct{}{}:
+		materials, err = pm.cmm.GetEncryptionMaterials(pm.ctx, pool.request)
+		<-pm.refillSem
+	case <-pm.ctx.Done():
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pool.pending--
+	if err != nil {
+		pool.failedAt = time.Now()
+		return
+	}
+	if materials == nil {
+		return
+	}
+	if pm.closed || pool.evicted {
+		destroyMaterials(materials)
+		return
+	}
+	pool.items = append(pool.items, pooledMaterials{materials: materials, createdAt: time.Now()})
+}
+
+// evictLeastRecentlyUsed removes the pool with the oldest take and wipes its
+// pooled materials. pm.mu must be held.
+func (pm *PoolingCryptoMaterialsManager) evictLeastRecentlyUsed() {
+	var lruKey string
+	var lru *materialsPool
+	for key, pool := range pm.pools {
+		if lru == nil || pool.usedAt.Before(lru.usedAt) {
+			lruKey, lru = key, pool
+		}
+	}
+	if lru != nil {
+		pm.evict(lruKey, lru)
+	}
+}
+
+// evict removes pool from pools and wipes its pooled materials, materials of
+// in-flight refills are wiped once generated. pm.mu must be held.
+func (pm *PoolingCryptoMaterialsManager) evict(key string, pool *materialsPool) {
+	for _, item := range pool.items {
+		destroyMaterials(item.materials)
+	}
+	pool.items = nil
+	pool.evicted = true
+	delete(pm.pools, key)
+}
+
+// sweepLoop periodically sweeps expired materials and idle pools until Close.
+func (pm *PoolingCryptoMaterialsManager) sweepLoop() {
+	defer pm.wg.Done()
+
+	interval := pm.maxKeyAge / 2
+	if interval < poolMinSweepInterval {
+		interval = poolMinSweepInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pm.sweep()
+		case <-pm.ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep wipes pooled materials older than max key age, and removes pools
+// which have no materials left and were not used for longer than max key age.
+// Pools are not refilled here, only on take.
+func (pm *PoolingCryptoMaterialsManager) sweep() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.closed {
+		return
+	}
+	for key, pool := range pm.pools {
+		expired := 0
+		for _, item := range pool.items {
+			if time.Since(item.createdAt) <= pm.maxKeyAge {
+				break
+			}
+			destroyMaterials(item.materials)
+			expired++
+		}
+		if expired > 0 {
+			// items are in creation order, expired are at the front
+			n := copy(pool.items, pool.items[expired:])
+			for i := n; i < len(pool.items); i++ {
+				pool.items[i] = pooledMaterials{}
+			}
+			pool.items = pool.items[:n]
+		}
+		if len(pool.items) == 0 && pool.pending == 0 && time.Since(pool.usedAt) > pm.maxKeyAge {
+			pm.evict(key, pool)
+		}
+	}
+}
+
+func copyEncryptionContext(ec suite.EncryptionContext) suite.EncryptionContext {
+	if ec == nil {
+		return nil
+	}
+	c := make(suite.EncryptionContext, len(ec))
+	for k, v := range ec {
+		c[k] = v
+	}
+	return c
+}
+
+// destroyMaterials wipes key material of m if it implements [model.Destroyer].
+func destroyMaterials(m model.EncryptionMaterial) {
+	if d, ok := m.(model.Destroyer); ok {
+		d.Destroy()
+	}
+}
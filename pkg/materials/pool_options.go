@@ -0,0 +1,80 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPoolSize is the default number of data keys kept per pool shape.
+	DefaultPoolSize = 8
+	// DefaultPoolRefillConcurrency is the default number of data keys generated concurrently.
+	DefaultPoolRefillConcurrency = 2
+	// DefaultPoolMaxKeyAge is how long pooled data keys are used by default.
+	DefaultPoolMaxKeyAge = 5 * time.Minute
+	// DefaultPoolMaxPools is the default number of pool shapes kept at once.
+	DefaultPoolMaxPools = 64
+)
+
+// PoolOptions are the PoolingCryptoMaterialsManager options.
+type PoolOptions struct {
+	size              int
+	refillConcurrency int
+	maxKeyAge         time.Duration
+	maxPools          int
+}
+
+// PoolOptionsFunc configures PoolingCryptoMaterialsManager.
+type PoolOptionsFunc func(o *PoolOptions) error
+
+// WithPoolSize sets how many data keys are kept ready for each algorithm suite
+// and encryption context, DefaultPoolSize by default.
+func WithPoolSize(n int) PoolOptionsFunc {
+	return func(o *PoolOptions) error {
+		if n < 1 {
+			return fmt.Errorf("pool size must be positive")
+		}
+		o.size = n
+		return nil
+	}
+}
+
+// WithPoolRefillConcurrency sets how many data keys are generated concurrently
+// in background across all pools, DefaultPoolRefillConcurrency by default.
+func WithPoolRefillConcurrency(n int) PoolOptionsFunc {
+	return func(o *PoolOptions) error {
+		if n < 1 {
+			return fmt.Errorf("pool refill concurrency must be positive")
+		}
+		o.refillConcurrency = n
+		return nil
+	}
+}
+
+// WithPoolMaxKeyAge sets how long a generated data key can wait in the pool,
+// older keys are discarded. DefaultPoolMaxKeyAge by default.
+func WithPoolMaxKeyAge(d time.Duration) PoolOptionsFunc {
+	return func(o *PoolOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("pool max key age must be positive")
+		}
+		o.maxKeyAge = d
+		return nil
+	}
+}
+
+// WithPoolMaxPools sets how many pools, one per algorithm suite and encryption
+// context, are kept at once. When a new pool is needed, the least recently used
+// pool is evicted and its data keys are wiped. DefaultPoolMaxPools by default.
+func WithPoolMaxPools(n int) PoolOptionsFunc {
+	return func(o *PoolOptions) error {
+		if n < 1 {
+			return fmt.Errorf("pool max pools must be positive")
+		}
+		o.maxPools = n
+		return nil
+	}
+}
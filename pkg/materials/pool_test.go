@@ -0,0 +1,270 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/chainifynet/aws-encryption-sdk-go/mocks/github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// newUniqueMaterialsCMM returns mock CMM which generates materials with a
+// unique data key on every call, and the generated materials.
+func newUniqueMaterialsCMM(t *testing.T) (*mocks.MockCryptoMaterialsManager, *[]model.EncryptionMaterial, *sync.Mutex) {
+	var mu sync.Mutex
+	var generated []model.EncryptionMaterial
+	var n atomic.Int32
+	cmm := mocks.NewMockCryptoMaterialsManager(t)
+	cmm.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, req model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+			dataKey := model.NewDataKey(model.WithKeyMeta("raw", "key1"), []byte(fmt.Sprintf("%032d", n.Add(1))), []byte("encrypted"))
+			m := model.NewEncryptionMaterials(dataKey, nil, req.EncryptionContext, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			generated = append(generated, m)
+			return m, nil
+		}).Maybe()
+	return cmm, &generated, &mu
+}
+
+func poolLen(pm *PoolingCryptoMaterialsManager, req model.EncryptionMaterialsRequest) int {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pool, ok := pm.pools[string(encryptionCacheKey(nil, req.Algorithm, req.EncryptionContext))]
+	if !ok {
+		return 0
+	}
+	return len(pool.items)
+}
+
+func Test_NewPooling(t *testing.T) {
+	cmm := mocks.NewMockCryptoMaterialsManager(t)
+	tests := []struct {
+		name    string
+		cmm     model.CryptoMaterialsManager
+		opts    []PoolOptionsFunc
+		wantErr bool
+	}{
+		{"defaults", cmm, nil, false},
+		{"all options", cmm, []PoolOptionsFunc{WithPoolSize(1), WithPoolRefillConcurrency(4), WithPoolMaxKeyAge(time.Second), WithPoolMaxPools(2)}, false},
+		{"nil cmm", nil, nil, true},
+		{"zero size", cmm, []PoolOptionsFunc{WithPoolSize(0)}, true},
+		{"zero concurrency", cmm, []PoolOptionsFunc{WithPoolRefillConcurrency(0)}, true},
+		{"zero max key age", cmm, []PoolOptionsFunc{WithPoolMaxKeyAge(0)}, true},
+		{"zero max pools", cmm, []PoolOptionsFunc{WithPoolMaxPools(0)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPooling(tt.cmm, tt.opts...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCMM)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, got.Close())
+		})
+	}
+}
+
+func TestPoolingCryptoMaterialsManager_GetEncryptionMaterials(t *testing.T) {
+	underlying, generated, mu := newUniqueMaterialsCMM(t)
+	pm, err := NewPooling(underlying, WithPoolSize(3), WithPoolRefillConcurrency(2))
+	require.NoError(t, err)
+	defer pm.Close()
+
+	req := model.EncryptionMaterialsRequest{
+		EncryptionContext: suite.EncryptionContext{"purpose": "test"},
+		Algorithm:         suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		PlaintextLength:   10,
+	}
+
+	seen := make(map[string]struct{})
+	// the first request of a shape goes to the underlying CMM
+	first, err := pm.GetEncryptionMaterials(context.Background(), req)
+	require.NoError(t, err)
+	seen[string(first.DataEncryptionKey().DataKey())] = struct{}{}
+	assert.Eventually(t, func() bool { return poolLen(pm, req) == 3 }, time.Second, time.Millisecond)
+
+	// other shape has its own pool
+	other := req
+	other.EncryptionContext = suite.EncryptionContext{"purpose": "other"}
+	_, err = pm.GetEncryptionMaterials(context.Background(), other)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return poolLen(pm, other) == 3 }, time.Second, time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		m, err := pm.GetEncryptionMaterials(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, req.EncryptionContext, m.EncryptionContext())
+		// every data key is used once
+		assert.NotContains(t, seen, string(m.DataEncryptionKey().DataKey()))
+		seen[string(m.DataEncryptionKey().DataKey())] = struct{}{}
+	}
+	assert.Eventually(t, func() bool { return poolLen(pm, req) == 3 }, time.Second, time.Millisecond)
+
+	require.NoError(t, pm.Close())
+	// pooled materials are wiped on close
+	mu.Lock()
+	for _, m := range (*generated)[len(*generated)-3:] {
+		assert.Equal(t, make([]byte, 32), m.DataEncryptionKey().DataKey())
+	}
+	mu.Unlock()
+
+	_, err = pm.GetEncryptionMaterials(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCMM)
+	assert.NoError(t, pm.Close())
+}
+
+func TestPoolingCryptoMaterialsManager_MaxKeyAge(t *testing.T) {
+	underlying, generated, mu := newUniqueMaterialsCMM(t)
+	pm, err := NewPooling(underlying, WithPoolSize(2), WithPoolMaxKeyAge(time.Hour))
+	require.NoError(t, err)
+	defer pm.Close()
+
+	req := model.EncryptionMaterialsRequest{Algorithm: suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, PlaintextLength: -1}
+	_, err = pm.GetEncryptionMaterials(context.Background(), req)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return poolLen(pm, req) == 2 }, time.Second, time.Millisecond)
+	mu.Lock()
+	pooled := append([]model.EncryptionMaterial{}, (*generated)[1:3]...)
+	mu.Unlock()
+	// age pooled keys past max key age
+	pm.mu.Lock()
+	for _, pool := range pm.pools {
+		for i := range pool.items {
+			pool.items[i].createdAt = time.Now().Add(-2 * time.Hour)
+		}
+	}
+	pm.mu.Unlock()
+
+	m, err := pm.GetEncryptionMaterials(context.Background(), req)
+	require.NoError(t, err)
+	// expired keys are discarded and wiped, fresh key comes from the underlying CMM
+	for _, p := range pooled {
+		assert.Equal(t, make([]byte, 32), p.DataEncryptionKey().DataKey())
+		assert.NotSame(t, p, m)
+	}
+}
+
+func TestPoolingCryptoMaterialsManager_IdleExpiry(t *testing.T) {
+	underlying, generated, mu := newUniqueMaterialsCMM(t)
+	pm, err := NewPooling(underlying, WithPoolSize(2), WithPoolMaxKeyAge(50*time.Millisecond))
+	require.NoError(t, err)
+	defer pm.Close()
+
+	req := model.EncryptionMaterialsRequest{Algorithm: suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, PlaintextLength: -1}
+	_, err = pm.GetEncryptionMaterials(context.Background(), req)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return poolLen(pm, req) == 2 }, time.Second, time.Millisecond)
+	mu.Lock()
+	pooled := append([]model.EncryptionMaterial{}, (*generated)[1:3]...)
+	mu.Unlock()
+
+	// idle pool keys are wiped and the pool is removed without take
+	assert.Eventually(t, func() bool {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		return len(pm.pools) == 0
+	}, time.Second, time.Millisecond)
+	for _, p := range pooled {
+		assert.Equal(t, make([]byte, 32), p.DataEncryptionKey().DataKey())
+	}
+	// idle pool is not refilled
+	mu.Lock()
+	assert.Len(t, *generated, 3)
+	mu.Unlock()
+}
+
+func TestPoolingCryptoMaterialsManager_MaxPools(t *testing.T) {
+	underlying, generated, mu := newUniqueMaterialsCMM(t)
+	pm, err := NewPooling(underlying, WithPoolSize(1), WithPoolMaxPools(2))
+	require.NoError(t, err)
+	defer pm.Close()
+
+	reqs := make([]model.EncryptionMaterialsRequest, 3)
+	for i := range reqs {
+		reqs[i] = model.EncryptionMaterialsRequest{
+			EncryptionContext: suite.EncryptionContext{"id": fmt.Sprint(i)},
+			Algorithm:         suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+			PlaintextLength:   -1,
+		}
+	}
+	for _, req := range reqs[:2] {
+		_, err = pm.GetEncryptionMaterials(context.Background(), req)
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return poolLen(pm, req) == 1 }, time.Second, time.Millisecond)
+	}
+	// the second generated materials is pooled for id 0
+	mu.Lock()
+	pooled := (*generated)[1]
+	mu.Unlock()
+	require.Equal(t, "0", pooled.EncryptionContext()["id"])
+
+	// the least recently used pool is evicted and its keys are wiped
+	_, err = pm.GetEncryptionMaterials(context.Background(), reqs[2])
+	require.NoError(t, err)
+	pm.mu.Lock()
+	assert.Len(t, pm.pools, 2)
+	pm.mu.Unlock()
+	assert.Equal(t, 0, poolLen(pm, reqs[0]))
+	assert.Equal(t, make([]byte, 32), pooled.DataEncryptionKey().DataKey())
+	assert.Eventually(t, func() bool { return poolLen(pm, reqs[2]) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestPoolingCryptoMaterialsManager_RefillError(t *testing.T) {
+	underlying := mocks.NewMockCryptoMaterialsManager(t)
+	var calls atomic.Int32
+	underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+		RunAndReturn(func(context.Context, model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+			calls.Add(1)
+			return nil, fmt.Errorf("no keys: %w", ErrCMM)
+		})
+	pm, err := NewPooling(underlying, WithPoolSize(2))
+	require.NoError(t, err)
+	defer pm.Close()
+
+	req := model.EncryptionMaterialsRequest{Algorithm: suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, PlaintextLength: -1}
+	_, err = pm.GetEncryptionMaterials(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCMM)
+	assert.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, time.Millisecond)
+
+	// refill is not retried right after an error
+	_, err = pm.GetEncryptionMaterials(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCMM)
+	assert.Eventually(t, func() bool {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		return pm.pools[string(encryptionCacheKey(nil, req.Algorithm, req.EncryptionContext))].pending == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestPoolingCryptoMaterialsManager_DecryptMaterials(t *testing.T) {
+	underlying := mocks.NewMockCryptoMaterialsManager(t)
+	underlying.EXPECT().DecryptMaterials(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, req model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+			return newTestDecryptionMaterials(req)
+		}).Twice()
+	pm, err := NewPooling(underlying)
+	require.NoError(t, err)
+	defer pm.Close()
+	assert.Same(t, pm, pm.GetInstance())
+
+	for i := 0; i < 2; i++ {
+		_, err = pm.DecryptMaterials(context.Background(), model.DecryptionMaterialsRequest{Algorithm: suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY})
+		assert.NoError(t, err)
+	}
+}
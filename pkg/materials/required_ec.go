@@ -0,0 +1,166 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// RequiredEncryptionContextCMM makes encryption context keys mandatory and
+// keeps them out of the message header.
+//
+// Required keys must be present in the encryption context on encrypt. They are
+// cryptographically bound to the message by header authentication, but not
+// stored in the header. To decrypt the message, the caller must supply their
+// values with the reproduced encryption context, e.g. client
+// WithRequiredEncryptionContext decrypt option, otherwise decryption fails.
+type RequiredEncryptionContextCMM struct {
+	cmm          model.CryptoMaterialsManager
+	requiredKeys []string
+}
+
+// compile checking that RequiredEncryptionContextCMM implements CryptoMaterialsManager interface
+var _ model.CryptoMaterialsManager = (*RequiredEncryptionContextCMM)(nil)
+
+// NewRequiredEncryptionContext returns RequiredEncryptionContextCMM which
+// requires requiredKeys in the encryption context of cmm materials.
+//
+// Parameters:
+//   - cmm: underlying [model.CryptoMaterialsManager].
+//   - requiredKeys: encryption context keys which must be supplied on encrypt and decrypt.
+//
+// Returns:
+//   - *RequiredEncryptionContextCMM: required encryption context CMM.
+//   - error: if cmm is nil, requiredKeys is empty or has an empty key.
+//
+// Example usage:
+//
+//	cmm, err := materials.NewDefault(kmsKeyProvider)
+//	if err != nil {
+//		panic(err)
+//	}
+//	requiredCMM, err := materials.NewRequiredEncryptionContext(cmm, "tenant")
+//	if err != nil {
+//		panic(err)
+//	}
+//	ciphertext, _, err := sdkClient.Encrypt(ctx, plaintext, map[string]string{"tenant": "alice"}, requiredCMM)
+//	// ...
+//	plaintext, _, err = sdkClient.Decrypt(ctx, ciphertext, requiredCMM,
+//		client.WithRequiredEncryptionContext(map[string]string{"tenant": "alice"}))
+func NewRequiredEncryptionContext(cmm model.CryptoMaterialsManager, requiredKeys ...string) (*RequiredEncryptionContextCMM, error) {
+	if cmm == nil {
+		return nil, fmt.Errorf("underlying CMM must not be nil: %w", ErrCMM)
+	}
+	if len(requiredKeys) == 0 {
+		return nil, fmt.Errorf("required encryption context keys must not be empty: %w", ErrCMM)
+	}
+	keys := make([]string, 0, len(requiredKeys))
+	seen := make(map[string]struct{}, len(requiredKeys))
+	for _, k := range requiredKeys {
+		if k == "" {
+			return nil, fmt.Errorf("required encryption context key must not be empty: %w", ErrCMM)
+		}
+		if k == encryptedContextAWSKey {
+			return nil, fmt.Errorf("%s is reserved: %w", k, ErrCMM)
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &RequiredEncryptionContextCMM{
+		cmm:          cmm,
+		requiredKeys: keys,
+	}, nil
+}
+
+func (rm *RequiredEncryptionContextCMM) GetEncryptionMaterials(ctx context.Context, encReq model.EncryptionMaterialsRequest) (model.EncryptionMaterial, error) {
+	for _, k := range rm.requiredKeys {
+		if _, ok := encReq.EncryptionContext[k]; !ok {
+			return nil, fmt.Errorf("required key %q is missing in encryption context: %w", k, ErrCMM)
+		}
+	}
+
+	m, err := rm.cmm.GetEncryptionMaterials(ctx, encReq)
+	if err != nil {
+		return nil, err
+	}
+
+	materials := model.NewEncryptionMaterials(m.DataEncryptionKey(), m.EncryptedDataKeys(), m.EncryptionContext(), m.SigningKey())
+	for _, k := range rm.requiredKeys {
+		if _, ok := materials.EncryptionContext()[k]; !ok {
+			if d, ok := m.(model.Destroyer); ok {
+				d.Destroy()
+			}
+			return nil, fmt.Errorf("required key %q is missing in materials encryption context: %w", k, ErrCMM)
+		}
+	}
+	return materials.WithRequiredEncryptionContextKeys(mergeKeys(requiredEncryptionContextKeys(m), rm.requiredKeys)), nil
+}
+
+func (rm *RequiredEncryptionContextCMM) DecryptMaterials(ctx context.Context, decReq model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+	// required pairs are not stored in the message, so they come from the caller
+	ec := make(suite.EncryptionContext, len(decReq.EncryptionContext)+len(rm.requiredKeys))
+	for k, v := range decReq.EncryptionContext {
+		ec[k] = v
+	}
+	for _, k := range rm.requiredKeys {
+		v, ok := decReq.ReproducedEncryptionContext[k]
+		if !ok {
+			return nil, fmt.Errorf("required key %q is missing in reproduced encryption context: %w", k, ErrCMM)
+		}
+		if stored, ok := ec[k]; ok && stored != v {
+			return nil, fmt.Errorf("value of required key %q differs from reproduced: %w", k, ErrCMM)
+		}
+		ec[k] = v
+	}
+	decReq.EncryptionContext = ec
+
+	m, err := rm.cmm.DecryptMaterials(ctx, decReq)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewDecryptionMaterials(m.DataKey(), m.VerificationKey()).
+		WithRequiredEncryptionContextKeys(mergeKeys(requiredEncryptionContextKeys(m), rm.requiredKeys)), nil
+}
+
+func (rm *RequiredEncryptionContextCMM) GetInstance() model.CryptoMaterialsManager {
+	return &RequiredEncryptionContextCMM{
+		cmm:          rm.cmm.GetInstance(),
+		requiredKeys: rm.requiredKeys,
+	}
+}
+
+// requiredEncryptionContextKeys returns required encryption context keys of m,
+// nil if m does not implement [model.RequiredEncryptionContextMaterial].
+func requiredEncryptionContextKeys(m any) []string {
+	if r, ok := m.(model.RequiredEncryptionContextMaterial); ok {
+		return r.RequiredEncryptionContextKeys()
+	}
+	return nil
+}
+
+// mergeKeys returns sorted union of a and b.
+func mergeKeys(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	seen := make(map[string]struct{}, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, k := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
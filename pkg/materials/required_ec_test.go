@@ -0,0 +1,133 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package materials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/chainifynet/aws-encryption-sdk-go/mocks/github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func Test_NewRequiredEncryptionContext(t *testing.T) {
+	cmm := mocks.NewMockCryptoMaterialsManager(t)
+	tests := []struct {
+		name     string
+		cmm      model.CryptoMaterialsManager
+		keys     []string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{"sorted unique", cmm, []string{"b", "a", "b"}, []string{"a", "b"}, false},
+		{"nil cmm", nil, []string{"a"}, nil, true},
+		{"no keys", cmm, nil, nil, true},
+		{"empty key", cmm, []string{"a", ""}, nil, true},
+		{"reserved key", cmm, []string{encryptedContextAWSKey}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRequiredEncryptionContext(tt.cmm, tt.keys...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCMM)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKeys, got.requiredKeys)
+		})
+	}
+}
+
+func TestRequiredEncryptionContextCMM_GetEncryptionMaterials(t *testing.T) {
+	tests := []struct {
+		name       string
+		ec         suite.EncryptionContext
+		materialEC suite.EncryptionContext
+		wantErr    bool
+	}{
+		{"present", suite.EncryptionContext{"tenant": "a", "purpose": "test"}, suite.EncryptionContext{"tenant": "a", "purpose": "test"}, false},
+		{"missing in request", suite.EncryptionContext{"purpose": "test"}, nil, true},
+		{"missing in materials", suite.EncryptionContext{"tenant": "a"}, suite.EncryptionContext{"purpose": "test"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := mocks.NewMockCryptoMaterialsManager(t)
+			if tt.materialEC != nil {
+				dataKey := model.NewDataKey(model.WithKeyMeta("raw", "key1"), []byte("0123456789abcdef0123456789abcdef"), []byte("encrypted"))
+				underlying.EXPECT().GetEncryptionMaterials(mock.Anything, mock.Anything).
+					Return(model.NewEncryptionMaterials(dataKey, nil, tt.materialEC, nil), nil).Once()
+			}
+
+			cm, err := NewRequiredEncryptionContext(underlying, "tenant")
+			require.NoError(t, err)
+
+			got, err := cm.GetEncryptionMaterials(context.Background(), model.EncryptionMaterialsRequest{
+				EncryptionContext: tt.ec,
+				Algorithm:         suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+			})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCMM)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"tenant"}, requiredEncryptionContextKeys(got))
+			assert.Equal(t, tt.materialEC, got.EncryptionContext())
+		})
+	}
+}
+
+func TestRequiredEncryptionContextCMM_DecryptMaterials(t *testing.T) {
+	tests := []struct {
+		name       string
+		ec         suite.EncryptionContext
+		reproduced suite.EncryptionContext
+		wantEC     suite.EncryptionContext
+		wantErr    bool
+	}{
+		{"reproduced", suite.EncryptionContext{"purpose": "test"}, suite.EncryptionContext{"tenant": "a"}, suite.EncryptionContext{"tenant": "a", "purpose": "test"}, false},
+		{"stored and reproduced", suite.EncryptionContext{"tenant": "a"}, suite.EncryptionContext{"tenant": "a"}, suite.EncryptionContext{"tenant": "a"}, false},
+		{"not reproduced", suite.EncryptionContext{"purpose": "test"}, nil, nil, true},
+		{"stored differs", suite.EncryptionContext{"tenant": "b"}, suite.EncryptionContext{"tenant": "a"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := mocks.NewMockCryptoMaterialsManager(t)
+			if tt.wantEC != nil {
+				underlying.EXPECT().DecryptMaterials(mock.Anything, mock.MatchedBy(func(req model.DecryptionMaterialsRequest) bool {
+					return assert.ObjectsAreEqual(tt.wantEC, req.EncryptionContext)
+				})).RunAndReturn(func(_ context.Context, req model.DecryptionMaterialsRequest) (model.DecryptionMaterial, error) {
+					return newTestDecryptionMaterials(req)
+				}).Once()
+			}
+
+			cm, err := NewRequiredEncryptionContext(underlying, "tenant")
+			require.NoError(t, err)
+
+			got, err := cm.DecryptMaterials(context.Background(), model.DecryptionMaterialsRequest{
+				Algorithm:                   suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+				EncryptionContext:           tt.ec,
+				ReproducedEncryptionContext: tt.reproduced,
+			})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCMM)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"tenant"}, requiredEncryptionContextKeys(got))
+		})
+	}
+}
+
+func Test_mergeKeys(t *testing.T) {
+	assert.Equal(t, []string{"a"}, mergeKeys(nil, []string{"a"}))
+	assert.Equal(t, []string{"a", "b", "c"}, mergeKeys([]string{"c", "a"}, []string{"b", "a"}))
+}
@@ -7,12 +7,13 @@ import (
 	"crypto/ecdsa"
 
 	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/zeroize"
 )
 
 type EncryptionMaterialsRequest struct {
 	EncryptionContext suite.EncryptionContext
 	Algorithm         *suite.AlgorithmSuite
-	PlaintextLength   int
+	PlaintextLength   int // PlaintextLength is -1 when unknown, e.g. with streaming encryption
 }
 
 type EncryptionMaterials struct {
@@ -20,6 +21,7 @@ type EncryptionMaterials struct {
 	encryptedDataKeys []EncryptedDataKeyI
 	encryptionContext suite.EncryptionContext
 	signingKey        *ecdsa.PrivateKey
+	requiredECKeys    []string
 }
 
 func NewEncryptionMaterials(dataEncryptionKey DataKeyI, encryptedDataKeys []EncryptedDataKeyI, ec suite.EncryptionContext, signingKey *ecdsa.PrivateKey) *EncryptionMaterials {
@@ -42,15 +44,47 @@ func (e EncryptionMaterials) SigningKey() *ecdsa.PrivateKey {
 	return e.signingKey
 }
 
+// RequiredEncryptionContextKeys returns encryption context keys which are
+// authenticated but not stored in the message header.
+func (e EncryptionMaterials) RequiredEncryptionContextKeys() []string {
+	return e.requiredECKeys
+}
+
+// WithRequiredEncryptionContextKeys returns materials with required encryption
+// context keys set. Key material is shared, not copied.
+func (e EncryptionMaterials) WithRequiredEncryptionContextKeys(keys []string) *EncryptionMaterials {
+	e.requiredECKeys = keys
+	return &e
+}
+
+// Destroy wipes the plaintext data key and the signing key from memory.
+// Materials must not be used afterwards.
+func (e EncryptionMaterials) Destroy() {
+	if e.dataEncryptionKey != nil {
+		zeroize.Bytes(e.dataEncryptionKey.DataKey())
+	}
+	zeroize.ECDSAPrivateKey(e.signingKey)
+}
+
+var _ Destroyer = (*EncryptionMaterials)(nil)
+var _ RequiredEncryptionContextMaterial = (*EncryptionMaterials)(nil)
+
 type DecryptionMaterialsRequest struct {
 	Algorithm         *suite.AlgorithmSuite
 	EncryptedDataKeys []EncryptedDataKeyI
 	EncryptionContext suite.EncryptionContext
+	// ReproducedEncryptionContext is the encryption context the caller requires
+	// the message to be encrypted with, nil if none.
+	ReproducedEncryptionContext suite.EncryptionContext
+	// MaxDecryptAttempts is the maximum number of encrypted data key decryption
+	// attempts across all key providers, 0 is unlimited.
+	MaxDecryptAttempts int
 }
 
 type DecryptionMaterials struct {
 	dataKey         DataKeyI
 	verificationKey []byte
+	requiredECKeys  []string
 }
 
 func NewDecryptionMaterials(dataKey DataKeyI, verificationKey []byte) *DecryptionMaterials {
@@ -64,3 +98,26 @@ func (d DecryptionMaterials) DataKey() DataKeyI {
 func (d DecryptionMaterials) VerificationKey() []byte {
 	return d.verificationKey
 }
+
+// RequiredEncryptionContextKeys returns encryption context keys which are
+// authenticated but not stored in the message header.
+func (d DecryptionMaterials) RequiredEncryptionContextKeys() []string {
+	return d.requiredECKeys
+}
+
+// WithRequiredEncryptionContextKeys returns materials with required encryption
+// context keys set. Key material is shared, not copied.
+func (d DecryptionMaterials) WithRequiredEncryptionContextKeys(keys []string) *DecryptionMaterials {
+	d.requiredECKeys = keys
+	return &d
+}
+
+// Destroy wipes the plaintext data key from memory. Materials must not be used afterwards.
+func (d DecryptionMaterials) Destroy() {
+	if d.dataKey != nil {
+		zeroize.Bytes(d.dataKey.DataKey())
+	}
+}
+
+var _ Destroyer = (*DecryptionMaterials)(nil)
+var _ RequiredEncryptionContextMaterial = (*DecryptionMaterials)(nil)
@@ -27,3 +27,18 @@ type EncryptionMaterial interface {
 	EncryptionContext() suite.EncryptionContext
 	SigningKey() *ecdsa.PrivateKey
 }
+
+// Destroyer is an optional interface of EncryptionMaterial and DecryptionMaterial.
+// Destroy wipes key material from memory, it is called once the message is done
+// with the materials, whether encryption or decryption succeeded or not.
+type Destroyer interface {
+	Destroy()
+}
+
+// RequiredEncryptionContextMaterial is an optional interface of EncryptionMaterial
+// and DecryptionMaterial. RequiredEncryptionContextKeys returns encryption context
+// keys which are authenticated but not stored in the message header. Materials
+// not implementing it require no such keys.
+type RequiredEncryptionContextMaterial interface {
+	RequiredEncryptionContextKeys() []string
+}
@@ -0,0 +1,66 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/model"
+)
+
+type decryptAttemptsKey struct{}
+
+// DecryptAttempts limits the number of encrypted data key decryption attempts
+// for a single message, counted across all key providers.
+//
+// It is safe for concurrent use. A nil DecryptAttempts is unlimited.
+type DecryptAttempts struct {
+	mu      sync.Mutex
+	limit   int
+	used    int
+	skipped []model.KeyMeta
+}
+
+// NewDecryptAttempts returns DecryptAttempts allowing at most limit attempts.
+func NewDecryptAttempts(limit int) *DecryptAttempts {
+	return &DecryptAttempts{limit: limit}
+}
+
+// WithDecryptAttempts returns a copy of ctx carrying attempts.
+func WithDecryptAttempts(ctx context.Context, attempts *DecryptAttempts) context.Context {
+	return context.WithValue(ctx, decryptAttemptsKey{}, attempts)
+}
+
+// DecryptAttemptsFromContext returns DecryptAttempts carried by ctx, nil if none.
+func DecryptAttemptsFromContext(ctx context.Context) *DecryptAttempts {
+	attempts, _ := ctx.Value(decryptAttemptsKey{}).(*DecryptAttempts)
+	return attempts
+}
+
+// Acquire reports whether an attempt to decrypt the encrypted data key identified
+// by key is allowed, and counts it. A disallowed attempt is recorded as skipped.
+func (a *DecryptAttempts) Acquire(key model.KeyMeta) bool {
+	if a == nil {
+		return true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.used >= a.limit {
+		a.skipped = append(a.skipped, key)
+		return false
+	}
+	a.used++
+	return true
+}
+
+// Skipped returns encrypted data keys whose decryption attempts were not allowed.
+func (a *DecryptAttempts) Skipped() []model.KeyMeta {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]model.KeyMeta(nil), a.skipped...)
+}
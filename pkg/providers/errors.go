@@ -13,4 +13,5 @@ var (
 	ErrMasterKeyProviderNoPrimaryKey     = errors.New("MKP no primary key")
 	ErrConfig                            = errors.New("MKP config error")
 	ErrFilterKeyNotAllowed               = errors.New("MKP key not allowed by filter")
+	ErrMaxDecryptAttempts                = errors.New("MKP max decrypt attempts reached")
 )
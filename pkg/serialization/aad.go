@@ -130,6 +130,10 @@ func (d *aadData) Bytes() []byte {
 // used during decryption process
 func (d *aadData) AsEncryptionContext() suite.EncryptionContext {
 	ec := make(suite.EncryptionContext)
+	if d == nil {
+		// header without AAD has empty encryption context
+		return ec
+	}
 
 	for _, pair := range d.kv {
 		ec[pair.key] = pair.value
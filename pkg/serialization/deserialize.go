@@ -21,7 +21,7 @@ func DeserializeHeader(buf *bytes.Buffer, maxEncryptedDataKeys int) (*MessageHea
 		return nil, nil, errEdk
 	}
 
-	authData, err := MessageHeaderAuth.Deserialize(buf)
+	authData, err := MessageHeaderAuth.Deserialize(buf, header.AlgorithmSuite)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -46,3 +46,17 @@ func DeserializeBody(buf *bytes.Buffer, algorithm *suite.AlgorithmSuite, frameLe
 
 	return deserializedBody, nil
 }
+
+//goland:noinspection GoExportedFuncWithUnexportedType
+func DeserializeNonFramedBody(buf *bytes.Buffer, algorithm *suite.AlgorithmSuite) (*nonFramedBody, error) { //nolint:revive
+	deserializedBody, err := MessageBody.nonFramedFromBuffer(algorithm, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm.IsSigning() && buf.Len() > (lenFieldBytes+algorithm.Authentication.SignatureLen) {
+		return nil, errors.New("malformed large message")
+	}
+
+	return deserializedBody, nil
+}
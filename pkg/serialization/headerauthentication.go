@@ -6,6 +6,8 @@ package serialization
 import (
 	"bytes"
 	"fmt"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 )
 
 const (
@@ -17,6 +19,7 @@ var MessageHeaderAuth mha //nolint:gochecknoglobals
 type mha struct{}
 
 type headerAuth struct {
+	iv                 []byte // 12 bytes, iv is present only in message format version 1
 	authenticationData []byte // 16 bytes, authenticationData is auth tag
 }
 
@@ -24,6 +27,11 @@ func (ha headerAuth) AuthData() []byte {
 	return ha.authenticationData
 }
 
+// IV returns header authentication IV, nil for message format version 2.
+func (ha headerAuth) IV() []byte {
+	return ha.iv
+}
+
 func (h mha) New(authData []byte) (*headerAuth, error) {
 	if len(authData) != headerAuthDataLen {
 		return nil, fmt.Errorf("incorect len of authData %d", len(authData))
@@ -31,24 +39,52 @@ func (h mha) New(authData []byte) (*headerAuth, error) {
 	return &headerAuth{authenticationData: authData}, nil
 }
 
+// NewWithIV creates message format version 1 header authentication
+// which carries IV along with auth tag.
+func (h mha) NewWithIV(iv, authData []byte) (*headerAuth, error) {
+	if len(iv) == 0 {
+		return nil, fmt.Errorf("empty header auth IV")
+	}
+	ha, err := h.New(authData)
+	if err != nil {
+		return nil, err
+	}
+	ha.iv = iv
+	return ha, nil
+}
+
 func (ha headerAuth) Len() int {
-	return headerAuthDataLen // 16 bytes, headerAuth.authenticationData is auth tag
+	return len(ha.iv) + headerAuthDataLen // 12 bytes IV (format version 1 only) + 16 bytes, headerAuth.authenticationData is auth tag
 }
 
 func (ha headerAuth) Serialize() []byte {
 	var buf []byte
-	buf = make([]byte, 0, headerAuthDataLen)
+	buf = make([]byte, 0, ha.Len())
+	buf = append(buf, ha.iv...)
 	buf = append(buf, ha.authenticationData...)
 	return buf
 }
 
 // Deserialize can be private
 // TODO andrew change to private
-func (h mha) Deserialize(buf *bytes.Buffer) (*headerAuth, error) {
-	if buf.Len() < headerAuthDataLen {
+func (h mha) Deserialize(buf *bytes.Buffer, algorithm *suite.AlgorithmSuite) (*headerAuth, error) {
+	if algorithm == nil {
+		return nil, fmt.Errorf("invalid AlgorithmSuite: %v", algorithm)
+	}
+	ivLen := 0
+	if algorithm.MessageFormatVersion == suite.MessageFormatVersion1 {
+		ivLen = algorithm.EncryptionSuite.IVLen
+	}
+	if buf.Len() < ivLen+headerAuthDataLen {
 		return nil, fmt.Errorf("empty buffer")
 	}
 
+	if ivLen > 0 {
+		iv := buf.Next(ivLen)
+		authData := buf.Next(headerAuthDataLen)
+		return h.NewWithIV(iv, authData)
+	}
+
 	authData := buf.Next(headerAuthDataLen)
 	// TODO copy authData into new slice, otherwise authData capacity is equal to buf capacity
 
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
 )
 
 func Test_mha_New(t *testing.T) {
@@ -20,13 +22,13 @@ func Test_mha_New(t *testing.T) {
 		want    *headerAuth
 		wantErr bool
 	}{
-		{"auth_Nil_Header", args{nil}, &headerAuth{nil}, true},
-		{"auth_Nil_Header_2", args{[]uint8(nil)}, &headerAuth{nil}, true},
-		{"auth_With_ShortHeader", args{[]byte{0x01}}, &headerAuth{[]byte{0x01}}, true},
-		{"auth_Header_Valid_0", args{[]byte("validkeyvalidkey")}, &headerAuth{[]byte("validkeyvalidkey")}, false},
-		{"auth_large_header", args{[]byte("largeHeaderDatalargeHeaderDatalargeHeaderData")}, &headerAuth{[]byte{0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61}}, true},
-		{"auth_header_valid_1", args{[]byte("validkeyvalidkey")}, &headerAuth{[]byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, false},
-		{"auth_header_valid_2", args{[]byte("VALIDKEYVALIDKEY")}, &headerAuth{[]byte{0x56, 0x41, 0x4c, 0x49, 0x44, 0x4b, 0x45, 0x59, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x4b, 0x45, 0x59}}, false},
+		{"auth_Nil_Header", args{nil}, &headerAuth{authenticationData: nil}, true},
+		{"auth_Nil_Header_2", args{[]uint8(nil)}, &headerAuth{authenticationData: nil}, true},
+		{"auth_With_ShortHeader", args{[]byte{0x01}}, &headerAuth{authenticationData: []byte{0x01}}, true},
+		{"auth_Header_Valid_0", args{[]byte("validkeyvalidkey")}, &headerAuth{authenticationData: []byte("validkeyvalidkey")}, false},
+		{"auth_large_header", args{[]byte("largeHeaderDatalargeHeaderDatalargeHeaderData")}, &headerAuth{authenticationData: []byte{0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61}}, true},
+		{"auth_header_valid_1", args{[]byte("validkeyvalidkey")}, &headerAuth{authenticationData: []byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, false},
+		{"auth_header_valid_2", args{[]byte("VALIDKEYVALIDKEY")}, &headerAuth{authenticationData: []byte{0x56, 0x41, 0x4c, 0x49, 0x44, 0x4b, 0x45, 0x59, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x4b, 0x45, 0x59}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -119,6 +121,7 @@ func Test_headerAuth_Serialize(t *testing.T) {
 func Test_mha_Deserialize(t *testing.T) {
 	type args struct {
 		buf *bytes.Buffer
+		alg *suite.AlgorithmSuite
 	}
 	type wants struct {
 		want        *headerAuth
@@ -133,15 +136,19 @@ func Test_mha_Deserialize(t *testing.T) {
 		wants   wants
 		wantErr bool
 	}{
-		{"empty_buffer", args{bytes.NewBuffer(nil)}, wants{&headerAuth{nil}, 0, 0, 0, 0}, true},
-		{"invalid_length_buffer", args{bytes.NewBuffer([]byte("123"))}, wants{&headerAuth{nil}, 3, 3, 3, 3}, true},
-		{"exact_size_buffer", args{bytes.NewBuffer([]byte("validkeyvalidkey"))}, wants{&headerAuth{[]byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, 16, 16, 0, 16}, false},
-		{"much_bigger_buffer", args{bytes.NewBuffer([]byte("validkeyvalidkey123"))}, wants{&headerAuth{[]byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, 19, 19, 3, 19}, false},
+		{"empty_buffer", args{bytes.NewBuffer(nil), suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY}, wants{&headerAuth{authenticationData: nil}, 0, 0, 0, 0}, true},
+		{"nil_algorithm", args{bytes.NewBuffer([]byte("validkeyvalidkey")), nil}, wants{nil, 16, 16, 16, 16}, true},
+		{"invalid_length_buffer", args{bytes.NewBuffer([]byte("123")), suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY}, wants{&headerAuth{authenticationData: nil}, 3, 3, 3, 3}, true},
+		{"exact_size_buffer", args{bytes.NewBuffer([]byte("validkeyvalidkey")), suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY}, wants{&headerAuth{authenticationData: []byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, 16, 16, 0, 16}, false},
+		{"much_bigger_buffer", args{bytes.NewBuffer([]byte("validkeyvalidkey123")), suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY}, wants{&headerAuth{authenticationData: []byte{0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x6b, 0x65, 0x79}}, 19, 19, 3, 19}, false},
+		{"v1_short_buffer", args{bytes.NewBuffer([]byte("validkeyvalidkey")), suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256}, wants{nil, 16, 16, 16, 16}, true},
+		{"v1_exact_size_buffer", args{bytes.NewBuffer([]byte("ivivivivivivvalidkeyvalidkey")), suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256}, wants{&headerAuth{iv: []byte("iviviviviviv"), authenticationData: []byte("validkeyvalidkey")}, 28, 28, 0, 28}, false},
+		{"v1_much_bigger_buffer", args{bytes.NewBuffer([]byte("ivivivivivivvalidkeyvalidkey123")), suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256}, wants{&headerAuth{iv: []byte("iviviviviviv"), authenticationData: []byte("validkeyvalidkey")}, 31, 31, 3, 31}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := mha{}
-			got, err := h.Deserialize(tt.args.buf)
+			got, err := h.Deserialize(tt.args.buf, tt.args.alg)
 			assert.Equalf(t, tt.wants.bufLenAfter, tt.args.buf.Len(), "Deserialize() must read exactly number of bytes, buf.Len() = %v, want %v", tt.args.buf.Len(), tt.wants.bufLenAfter)
 			assert.Equalf(t, tt.wants.bufCapAfter, tt.args.buf.Cap(), "Deserialize() must not resize buffer, buf.Cap() = %v, want %v", tt.args.buf.Cap(), tt.wants.bufCapAfter)
 			assert.Equalf(t, tt.wants.bufCap, tt.args.buf.Cap(), "Deserialize() must not resize buffer, buf.Cap() = %v, want %v", tt.args.buf.Cap(), tt.wants.bufCap)
@@ -154,3 +161,31 @@ func Test_mha_Deserialize(t *testing.T) {
 		})
 	}
 }
+
+func Test_mha_NewWithIV(t *testing.T) {
+	tests := []struct {
+		name     string
+		iv       []byte
+		authData []byte
+		want     *headerAuth
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{"nil_iv", nil, []byte("validkeyvalidkey"), nil, assert.Error},
+		{"invalid_auth_data", []byte("iviviviviviv"), []byte("123"), nil, assert.Error},
+		{"valid", []byte("iviviviviviv"), []byte("validkeyvalidkey"), &headerAuth{iv: []byte("iviviviviviv"), authenticationData: []byte("validkeyvalidkey")}, assert.NoError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mha{}.NewWithIV(tt.iv, tt.authData)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			if got != nil {
+				assert.Equal(t, []byte("iviviviviviv"), got.IV())
+				assert.Equal(t, 28, got.Len())
+				assert.Equal(t, []byte("ivivivivivivvalidkeyvalidkey"), got.Serialize())
+			}
+		})
+	}
+}
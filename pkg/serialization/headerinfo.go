@@ -0,0 +1,69 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"io"
+	"math"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// HeaderInfo is a read-only view of an encrypted message header.
+// It contains no secret material and does not require decryption materials.
+type HeaderInfo struct {
+	Version           int                     // Version is message format version, 1 or 2.
+	Algorithm         *suite.AlgorithmSuite   // Algorithm is the algorithm suite used to encrypt the message.
+	MessageID         []byte                  // MessageID is the message identifier, 16 bytes in format version 1, 32 bytes in format version 2.
+	EncryptionContext suite.EncryptionContext // EncryptionContext is the encryption context stored in the message, including reserved keys.
+	EncryptedDataKeys []EncryptedDataKeyInfo  // EncryptedDataKeys are the encrypted data keys in the order stored in the message.
+	ContentType       suite.ContentType       // ContentType is framed or non-framed content.
+	FrameLength       int                     // FrameLength is the frame length of framed content, 0 for non-framed content.
+}
+
+// EncryptedDataKeyInfo identifies the master key an encrypted data key is encrypted with.
+type EncryptedDataKeyInfo struct {
+	ProviderID string // ProviderID is the key provider ID, "aws-kms" for AWS KMS keys.
+	KeyID      string // KeyID is the key provider info, AWS KMS key ARN for AWS KMS keys.
+	Length     int    // Length is the length of the encrypted data key in bytes.
+}
+
+// ParseHeader reads the message header and header authentication from r
+// without decrypting the message. It does not call any key provider.
+//
+// It returns the header view and the number of bytes consumed from r, which is
+// the offset of the message body. The header authentication is not verified,
+// header fields must not be trusted until the message is decrypted.
+//
+// The number of encrypted data keys is limited only by the message format,
+// up to 65535.
+func ParseHeader(r io.Reader) (*HeaderInfo, int, error) {
+	header, authData, err := ReadHeader(r, math.MaxUint16)
+	if err != nil {
+		return nil, 0, err
+	}
+	return header.info(), header.Len() + authData.Len(), nil
+}
+
+func (mh MessageHeader) info() *HeaderInfo {
+	edks := make([]EncryptedDataKeyInfo, 0, len(mh.EncryptedDataKeys))
+	for _, k := range mh.EncryptedDataKeys {
+		edks = append(edks, EncryptedDataKeyInfo{
+			ProviderID: string(k.ProviderID),
+			KeyID:      k.ProviderInfo,
+			Length:     k.encryptedDataKeyLen,
+		})
+	}
+	messageID := make([]byte, len(mh.MessageID))
+	copy(messageID, mh.MessageID)
+	return &HeaderInfo{
+		Version:           mh.AlgorithmSuite.MessageFormatVersion,
+		Algorithm:         mh.AlgorithmSuite,
+		MessageID:         messageID,
+		EncryptionContext: mh.AADData.AsEncryptionContext(),
+		EncryptedDataKeys: edks,
+		ContentType:       mh.contentType,
+		FrameLength:       mh.FrameLength,
+	}
+}
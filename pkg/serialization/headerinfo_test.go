@@ -0,0 +1,82 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func Test_ParseHeader(t *testing.T) {
+	edk1, _ := EDK.new(awsKmsProviderID, "arn:aws:kms:eu-west-1:123456789011:key/test1", []byte("encrypted1"))
+	edk2, _ := EDK.new("raw", "static1", []byte("encrypted22"))
+
+	tests := []struct {
+		name        string
+		alg         *suite.AlgorithmSuite
+		aad         *aadData
+		contentType suite.ContentType
+		frameLength int
+		wantEC      suite.EncryptionContext
+	}{
+		{"v2_framed", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, AAD.NewAADWithEncryptionContext(map[string]string{"purpose": "test", "a": "b"}), suite.FramedContent, 4096, suite.EncryptionContext{"purpose": "test", "a": "b"}},
+		{"v2_without_aad", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, nil, suite.FramedContent, 128, suite.EncryptionContext{}},
+		{"v1_non_framed", suite.AES_128_GCM_IV12_TAG16_HKDF_SHA256, AAD.NewAADWithEncryptionContext(map[string]string{"purpose": "test"}), suite.NonFramedContent, 0, suite.EncryptionContext{"purpose": "test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageID := bytes.Repeat([]byte{0x01}, tt.alg.MessageIDLen())
+			header, err := EncryptedMessageHeader.New(MessageHeaderParams{
+				AlgorithmSuite:     tt.alg,
+				MessageID:          messageID,
+				AADData:            tt.aad,
+				EncryptedDataKeys:  []encryptedDataKey{*edk1, *edk2},
+				ContentType:        tt.contentType,
+				FrameLength:        tt.frameLength,
+				AlgorithmSuiteData: bytes.Repeat([]byte{0x02}, tt.alg.AlgorithmSuiteDataLen()),
+			})
+			require.NoError(t, err)
+			auth, err := MessageHeaderAuth.New(bytes.Repeat([]byte{0x03}, headerAuthDataLen))
+			if tt.alg.MessageFormatVersion == suite.MessageFormatVersion1 {
+				auth, err = MessageHeaderAuth.NewWithIV(bytes.Repeat([]byte{0x04}, tt.alg.EncryptionSuite.IVLen), bytes.Repeat([]byte{0x03}, headerAuthDataLen))
+			}
+			require.NoError(t, err)
+
+			src := bytes.NewReader(append(append(header.Bytes(), auth.Serialize()...), []byte("body")...))
+			info, n, err := ParseHeader(src)
+			require.NoError(t, err)
+
+			assert.Equal(t, header.Len()+auth.Len(), n)
+			assert.Equal(t, tt.alg.MessageFormatVersion, info.Version)
+			assert.Equal(t, tt.alg, info.Algorithm)
+			assert.Equal(t, messageID, info.MessageID)
+			assert.Equal(t, tt.wantEC, info.EncryptionContext)
+			assert.Equal(t, tt.contentType, info.ContentType)
+			assert.Equal(t, tt.frameLength, info.FrameLength)
+			assert.Equal(t, []EncryptedDataKeyInfo{
+				{ProviderID: "aws-kms", KeyID: "arn:aws:kms:eu-west-1:123456789011:key/test1", Length: 10},
+				{ProviderID: "raw", KeyID: "static1", Length: 11},
+			}, info.EncryptedDataKeys)
+
+			rest, err := io.ReadAll(src)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("body"), rest)
+		})
+	}
+}
+
+func Test_ParseHeader_Errors(t *testing.T) {
+	_, n, err := ParseHeader(bytes.NewReader(nil))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 0, n)
+
+	_, _, err = ParseHeader(bytes.NewReader([]byte{0x02, 0x05, 0x78, 0x00}))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
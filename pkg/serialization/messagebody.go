@@ -110,6 +110,14 @@ func (b *body) Bytes() []byte {
 	return buf
 }
 
+// Flush returns serialized frames added since the last Flush and releases them.
+// Sequence number is preserved, so the next frame continues the sequence.
+func (b *body) Flush() []byte {
+	buf := b.Bytes()
+	b.frames = b.frames[:0]
+	return buf
+}
+
 func (b *body) Frames() []frame {
 	return b.frames
 }
@@ -243,19 +251,26 @@ func (bf frame) len() int {
 func (bf frame) Bytes() []byte {
 	var buf []byte
 	buf = make([]byte, 0, bf.len())
-	if bf.isFinal {
-		buf = append(buf, finalFrameIndicator...)
-	}
-	buf = append(buf, conv.FromInt.Uint32BigEndian(bf.sequenceNumber)...)
-	buf = append(buf, bf.iV...)
-	if bf.isFinal {
-		buf = append(buf, conv.FromInt.Uint32BigEndian(bf.contentLength)...)
-	}
+	buf = MessageBody.AppendFrameHeader(buf, bf.isFinal, bf.sequenceNumber, bf.iV, bf.contentLength)
 	buf = append(buf, bf.encryptedContent...)
 	buf = append(buf, bf.authenticationTag...)
 	return buf
 }
 
+// AppendFrameHeader appends the serialized frame fields preceding the encrypted
+// content to b. contentLength is serialized for the final frame only.
+func (mb messageBody) AppendFrameHeader(b []byte, final bool, seqNum int, IV []byte, contentLength int) []byte { //nolint:gocritic
+	if final {
+		b = append(b, finalFrameIndicator...)
+	}
+	b = append(b, conv.FromInt.Uint32BigEndian(seqNum)...)
+	b = append(b, IV...)
+	if final {
+		b = append(b, conv.FromInt.Uint32BigEndian(contentLength)...)
+	}
+	return b
+}
+
 func (bf frame) IsFinal() bool {
 	return bf.isFinal
 }
@@ -14,14 +14,20 @@ import (
 )
 
 var (
-	errHeaderInvalidVersion = errors.New("invalid version, expected format version 2")
+	errHeaderInvalidVersion = errors.New("invalid message format version")
 	errHeaderDeserialize    = errors.New("header deserialization error")
 )
 
 const (
-	minimumHeaderBufferLen = int(77)
+	minimumHeaderBufferLen   = int(77)
+	minimumHeaderBufferLenV1 = int(35)
+	messageTypeCustomerAED   = uint8(0x80) // messageTypeCustomerAED is the only defined message type in format version 1
+	reservedFieldBytes       = int(4)      // reservedFieldBytes is length of the format version 1 reserved field, always 0x00000000
 )
 
+// reservedField is the value of the format version 1 reserved field.
+var reservedField = []byte{0x00, 0x00, 0x00, 0x00} //nolint:gochecknoglobals
+
 // All AES-GCM algorithm suites have a 12-byte initialization vector and a 16-byte AES-GCM authentication tag.
 // reference https://docs.aws.amazon.com/encryption-sdk/latest/developer-guide/IV-reference.html
 
@@ -30,16 +36,19 @@ var EncryptedMessageHeader emh //nolint:gochecknoglobals
 type emh struct{}
 
 type MessageHeader struct {
-	// 											// 1, comes from AlgorithmSuite, message version, supported only version 2, always present as 0x02.
-	AlgorithmSuite        *suite.AlgorithmSuite // 2, AlgorithmID in AlgorithmSuite, supported only 0x0578 (1400) and 0x0478 (1144), always present. Reference (https://docs.aws.amazon.com/encryption-sdk/latest/developer-guide/algorithms-reference.html)
-	MessageID             []byte                // 32, MessageID (random 256-bit value). Always present. Algorithm suites with key commitment (algorithm ID 04xx and 05xx) for the extract step (HKDF with SHA-512) used as salt.
+	// 											// 1, comes from AlgorithmSuite, message version, 0x01 or 0x02, always present.
+	// 											// 1, format version 1 only, message type, always present as 0x80.
+	AlgorithmSuite        *suite.AlgorithmSuite // 2, AlgorithmID in AlgorithmSuite, always present. Reference (https://docs.aws.amazon.com/encryption-sdk/latest/developer-guide/algorithms-reference.html)
+	MessageID             []byte                // 32 (16 in format version 1), MessageID (random value). Always present. Algorithm suites with key commitment (algorithm ID 04xx and 05xx) for the extract step (HKDF with SHA-512) used as salt.
 	aadLen                int                   // 2, aadLen is AAD Length (When the encryption context is empty, the value of the AAD Length field is 0), length AADData in bytes not including aadLen
 	AADData               *aadData              // AADData is AAD Key-Value Pair (AADData) data (Key-Value Pair Count + []keyValuePair data). Bytes varies, aadLen = N bytes of AADData. present if aadLen > 0.
 	EncryptedDataKeyCount int                   // 2, EncryptedDataKeyCount is count of EncryptedDataKeys below, always present.
 	EncryptedDataKeys     []encryptedDataKey    // EncryptedDataKeys varies
-	contentType           suite.ContentType     // 2, contentType is 0x01 Non-Framed or 0x02 Framed content
-	FrameLength           int                   // 4, FrameLength is the length of each frame of framed data. It is a 4-byte value interpreted as a 32-bit unsigned integer that specifies the number of bytes in each frame. When the data is non-framed, that is, when the value of the contentType field is 0x01, this value must be 0.
-	AlgorithmSuiteData    []byte                // 32 bytes, AlgorithmSuiteData
+	contentType           suite.ContentType     // 1, contentType is 0x01 Non-Framed or 0x02 Framed content
+	// 											// 4, format version 1 only, reserved field, always present as 0x00000000.
+	// 											// 1, format version 1 only, IV length, comes from AlgorithmSuite, always present as 0x0C.
+	FrameLength        int    // 4, FrameLength is the length of each frame of framed data. It is a 4-byte value interpreted as a 32-bit unsigned integer that specifies the number of bytes in each frame. When the data is non-framed, that is, when the value of the contentType field is 0x01, this value must be 0.
+	AlgorithmSuiteData []byte // 32 bytes, AlgorithmSuiteData, format version 2 only
 }
 
 type MessageHeaderParams struct {
@@ -74,10 +83,16 @@ func (mh emh) New(p MessageHeaderParams) (*MessageHeader, error) {
 		aadLen = p.AADData.Len()
 		//return nil, fmt.Errorf("invalid AADData: %v", p.AADData)
 	}
-	if p.FrameLength < suite.MinFrameSize || p.FrameLength > suite.MaxFrameSize {
-		return nil, fmt.Errorf("%v frame length out of range", p.FrameLength)
-	}
-	if p.ContentType != suite.FramedContent {
+	switch p.ContentType {
+	case suite.FramedContent:
+		if p.FrameLength < suite.MinFrameSize || p.FrameLength > suite.MaxFrameSize {
+			return nil, fmt.Errorf("%v frame length out of range", p.FrameLength)
+		}
+	case suite.NonFramedContent:
+		if p.FrameLength != 0 {
+			return nil, fmt.Errorf("%v frame length must be 0 for non-framed content", p.FrameLength)
+		}
+	default:
 		return nil, fmt.Errorf("ContentType %v not supported", p.ContentType)
 	}
 
@@ -105,8 +120,15 @@ func (mh MessageHeader) Len() int {
 		edkLen += key.len()
 	}
 
+	versionFieldsLen := 0
+	if mh.isV1() {
+		// message type, reserved field, IV length
+		versionFieldsLen = singleFieldBytes + reservedFieldBytes + singleFieldBytes
+	}
+
 	// 1 + 32 + 2 + 2 + 101 + 2 + 272 + 1 + 4 + 32
 	return singleFieldBytes + // MessageHeader version of AlgorithmSuite
+		versionFieldsLen + // format version 1 fields
 		len(mh.AlgorithmSuite.IDBytes()) + // AlgorithmID of AlgorithmSuite
 		len(mh.MessageID) +
 		lenFieldBytes + // MessageHeader.aadLen field itself
@@ -122,9 +144,12 @@ func (mh MessageHeader) Bytes() []byte {
 	var buf []byte
 	buf = make([]byte, 0, mh.Len())
 	buf = append(buf, uint8(mh.AlgorithmSuite.MessageFormatVersion)) // 1, MessageFormatVersion of AlgorithmSuite
-	buf = append(buf, mh.AlgorithmSuite.IDBytes()...)                // 2, AlgorithmID of AlgorithmSuite
-	buf = append(buf, mh.MessageID...)                               // 32
-	buf = append(buf, conv.FromInt.Uint16BigEndian(mh.aadLen)...)    // 2
+	if mh.isV1() {
+		buf = append(buf, messageTypeCustomerAED) // 1, message type, format version 1 only
+	}
+	buf = append(buf, mh.AlgorithmSuite.IDBytes()...)             // 2, AlgorithmID of AlgorithmSuite
+	buf = append(buf, mh.MessageID...)                            // 32
+	buf = append(buf, conv.FromInt.Uint16BigEndian(mh.aadLen)...) // 2
 	if mh.aadLen > 0 && mh.AADData != nil {
 		buf = append(buf, mh.AADData.Bytes()...) // 2(count) + 25 + 29 + 45 = 101
 	}
@@ -132,22 +157,63 @@ func (mh MessageHeader) Bytes() []byte {
 	for _, key := range mh.EncryptedDataKeys {
 		buf = append(buf, key.bytes()...) // 272
 	}
-	buf = append(buf, uint8(mh.contentType))                           // 1
+	buf = append(buf, uint8(mh.contentType)) // 1
+	if mh.isV1() {
+		buf = append(buf, reservedField...)                               // 4, reserved field, format version 1 only
+		buf = append(buf, uint8(mh.AlgorithmSuite.EncryptionSuite.IVLen)) // 1, IV length, format version 1 only
+	}
 	buf = append(buf, conv.FromInt.Uint32BigEndian(mh.FrameLength)...) // 4
 	buf = append(buf, mh.AlgorithmSuiteData...)                        // 32
 	return buf
 }
 
+// AuthenticationData returns data which header authentication tag is computed
+// over: serialized header followed by serialized authOnly encryption context.
+// authOnly is authenticated but not stored in the header, e.g. required
+// encryption context, nothing is appended when it is empty.
+func (mh MessageHeader) AuthenticationData(authOnly suite.EncryptionContext) []byte {
+	if len(authOnly) == 0 {
+		return mh.Bytes()
+	}
+	return append(mh.Bytes(), AAD.NewAADWithEncryptionContext(authOnly).Bytes()...)
+}
+
+// ContentType returns the body content type, framed or non-framed.
+func (mh MessageHeader) ContentType() suite.ContentType {
+	return mh.contentType
+}
+
+// isV1 reports whether the header is serialized in message format version 1.
+func (mh MessageHeader) isV1() bool {
+	return mh.AlgorithmSuite.MessageFormatVersion == suite.MessageFormatVersion1
+}
+
 func (mh emh) fromBuffer(buf *bytes.Buffer) (*MessageHeader, error) {
-	if buf == nil {
+	if buf == nil || buf.Len() == 0 {
 		return nil, fmt.Errorf("empty buffer: %w", errHeaderDeserialize)
 	}
-	if buf.Len() < minimumHeaderBufferLen {
+
+	var minBufferLen int
+	switch int(buf.Bytes()[0]) {
+	case suite.MessageFormatVersion1:
+		minBufferLen = minimumHeaderBufferLenV1
+	case suite.MessageFormatVersion2:
+		minBufferLen = minimumHeaderBufferLen
+	default:
+		return nil, fmt.Errorf("%v message version not supported: %w", buf.Bytes()[0], errHeaderInvalidVersion)
+	}
+	if buf.Len() < minBufferLen {
 		return nil, fmt.Errorf("buffer too small: %w", errHeaderDeserialize)
 	}
 
 	version := fieldReader.ReadSingleField(buf)
 
+	if int(version) == suite.MessageFormatVersion1 {
+		if messageType := fieldReader.ReadSingleField(buf); messageType != messageTypeCustomerAED {
+			return nil, fmt.Errorf("%v message type not supported: %w", messageType, errHeaderDeserialize)
+		}
+	}
+
 	algorithmID := buf.Next(algorithmIDFieldBytes) // AlgorithmID is 2 bytes, uint16
 
 	// validate AlgorithmID is supported
@@ -183,24 +249,44 @@ func (mh emh) fromBuffer(buf *bytes.Buffer) (*MessageHeader, error) {
 		return nil, fmt.Errorf("empty buffer, cant read contentType: %w", errHeaderDeserialize)
 	}
 	contentType := fieldReader.ReadSingleField(buf)
-	if suite.ContentType(contentType) != suite.FramedContent {
+	if suite.ContentType(contentType) != suite.FramedContent && suite.ContentType(contentType) != suite.NonFramedContent {
 		return nil, fmt.Errorf("ContentType %v not supported: %w", contentType, errHeaderDeserialize)
 	}
 
+	if algorithmSuite.MessageFormatVersion == suite.MessageFormatVersion1 {
+		if buf.Len() < reservedFieldBytes+singleFieldBytes {
+			return nil, fmt.Errorf("empty buffer, cant read reserved field and IV length: %w", errHeaderDeserialize)
+		}
+		if reserved := buf.Next(reservedFieldBytes); !bytes.Equal(reserved, reservedField) {
+			return nil, fmt.Errorf("reserved field must be zero: %w", errHeaderDeserialize)
+		}
+		if ivLen := fieldReader.ReadSingleField(buf); int(ivLen) != algorithmSuite.EncryptionSuite.IVLen {
+			return nil, fmt.Errorf("%v IV length not equal to Algorithm defined: %w", ivLen, errHeaderDeserialize)
+		}
+	}
+
 	frameLength, err := fieldReader.ReadFrameField(buf)
 	if err != nil {
 		return nil, fmt.Errorf("cant read frameLength, %w", errHeaderDeserialize)
 	}
-	// validate min(128) and max(maxUint32) frame len
-	if frameLength < suite.MinFrameSize || frameLength > suite.MaxFrameSize {
+	// validate min(128) and max(maxUint32) frame len for framed content,
+	// non-framed content must have frame len 0
+	if suite.ContentType(contentType) == suite.NonFramedContent {
+		if frameLength != 0 {
+			return nil, fmt.Errorf("%v frame length must be 0 for non-framed content: %w", frameLength, errHeaderDeserialize)
+		}
+	} else if frameLength < suite.MinFrameSize || frameLength > suite.MaxFrameSize {
 		return nil, fmt.Errorf("%v frame length out of range: %w", frameLength, errHeaderDeserialize)
 	}
 
 	if buf.Len() < algorithmSuite.AlgorithmSuiteDataLen() {
 		return nil, fmt.Errorf("empty buffer, cant read algorithmSuiteData, %w", errHeaderDeserialize)
 	}
-	// should be 32
-	algorithmSuiteData := buf.Next(algorithmSuite.AlgorithmSuiteDataLen())
+	// should be 32 for format version 2, format version 1 has no algorithmSuiteData
+	var algorithmSuiteData []byte
+	if algorithmSuite.AlgorithmSuiteDataLen() > 0 {
+		algorithmSuiteData = buf.Next(algorithmSuite.AlgorithmSuiteDataLen())
+	}
 
 	log.Trace().MsgFunc(logger.FmtHex("AlgorithmSuiteData", algorithmSuiteData))
 
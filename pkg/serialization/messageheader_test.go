@@ -53,6 +53,29 @@ func Test_emh_New(t *testing.T) {
 		FrameLength:           1024,
 		AlgorithmSuiteData:    []byte("Algorithm12Algorithm12Algorithm1"),
 	}
+	mh4Mock := &MessageHeader{
+		AlgorithmSuite:        suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384,
+		MessageID:             []byte("MessageID12Messa"),
+		aadLen:                17,
+		AADData:               AAD.NewAADWithEncryptionContext(map[string]string{"test": "testing"}),
+		EncryptedDataKeyCount: 1,
+		EncryptedDataKeys:     []encryptedDataKey{*edk1Mock},
+		contentType:           suite.FramedContent,
+		FrameLength:           1024,
+		AlgorithmSuiteData:    nil,
+	}
+
+	mh5Mock := &MessageHeader{
+		AlgorithmSuite:        suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256,
+		MessageID:             []byte("MessageID12Messa"),
+		aadLen:                0,
+		AADData:               nil,
+		EncryptedDataKeyCount: 1,
+		EncryptedDataKeys:     []encryptedDataKey{*edk1Mock},
+		contentType:           suite.NonFramedContent,
+		FrameLength:           0,
+		AlgorithmSuiteData:    nil,
+	}
 
 	tests := []struct {
 		name           string
@@ -75,6 +98,11 @@ func Test_emh_New(t *testing.T) {
 		{"valid", args{MessageHeaderParams{suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, []byte("MessageID12MessageID12MessageID1"), nil, []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, []byte("Algorithm12Algorithm12Algorithm1")}}, mh1Mock, mh1Mock, false},
 		{"valid", args{MessageHeaderParams{suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, []byte("MessageID12MessageID12MessageID1"), AAD.NewAAD(), []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, []byte("Algorithm12Algorithm12Algorithm1")}}, mh2Mock, mh1Mock, false},
 		{"valid", args{MessageHeaderParams{suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, []byte("MessageID12MessageID12MessageID1"), AAD.NewAADWithEncryptionContext(map[string]string{"test": "testing"}), []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, []byte("Algorithm12Algorithm12Algorithm1")}}, mh3Mock, mh3Mock, false},
+		{"invalidMessageIDV1", args{MessageHeaderParams{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, []byte("MessageID12MessageID12MessageID1"), nil, []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, nil}}, nil, nil, true},
+		{"invalidAlgorithmSuiteDataLenV1", args{MessageHeaderParams{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, []byte("MessageID12Messa"), nil, []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, []byte("Algorithm12Algorithm12Algorithm1")}}, nil, nil, true},
+		{"invalidNonFramedFrameLength", args{MessageHeaderParams{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, []byte("MessageID12Messa"), nil, []encryptedDataKey{*edk1Mock}, suite.NonFramedContent, 1024, nil}}, nil, nil, true},
+		{"validNonFramed", args{MessageHeaderParams{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, []byte("MessageID12Messa"), nil, []encryptedDataKey{*edk1Mock}, suite.NonFramedContent, 0, nil}}, mh5Mock, mh5Mock, false},
+		{"validV1", args{MessageHeaderParams{suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, []byte("MessageID12Messa"), AAD.NewAADWithEncryptionContext(map[string]string{"test": "testing"}), []encryptedDataKey{*edk1Mock}, suite.FramedContent, 1024, nil}}, mh4Mock, mh4Mock, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -126,6 +154,13 @@ func Test_emh_fromBuffer(t *testing.T) {
 	frameLength := []byte{0x0, 0x0, 0x4, 0x0}
 	algorithmSuiteData := []byte{0x52, 0xdf, 0xed, 0x4c, 0x0, 0xb4, 0xd7, 0x95, 0x2f, 0xa8, 0x3c, 0x81, 0xdb, 0xee, 0xbe, 0x7f, 0x55, 0x9d, 0x48, 0x3e, 0x27, 0xd4, 0x18, 0xb6, 0x94, 0x49, 0xfb, 0xb8, 0xa6, 0x60, 0xdc, 0xe2}
 
+	messageFormatVersionV1 := []byte{0x1}
+	messageTypeV1 := []byte{0x80}
+	algorithmIDV1 := []byte{0x3, 0x78}
+	messageIDV1 := messageID[:16]
+	reservedV1 := []byte{0x0, 0x0, 0x0, 0x0}
+	ivLenV1 := []byte{0xc}
+
 	mh1Mock := &MessageHeader{
 		AlgorithmSuite:        suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384,
 		MessageID:             messageID,
@@ -137,6 +172,17 @@ func Test_emh_fromBuffer(t *testing.T) {
 		FrameLength:           1024,
 		AlgorithmSuiteData:    algorithmSuiteData,
 	}
+	mh2Mock := &MessageHeader{
+		AlgorithmSuite:        suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384,
+		MessageID:             messageIDV1,
+		aadLen:                188,
+		AADData:               mh1Mock.AADData,
+		EncryptedDataKeyCount: 2,
+		EncryptedDataKeys:     []encryptedDataKey{*edk1Mock, *edk2Mock},
+		contentType:           suite.FramedContent,
+		FrameLength:           1024,
+		AlgorithmSuiteData:    nil,
+	}
 	//argsBuf := new(bytes.Buffer)
 	concatSlices := func(slices ...[]byte) []byte {
 		var result []byte
@@ -172,6 +218,18 @@ func Test_emh_fromBuffer(t *testing.T) {
 		{"incompleteBuffer", args{bytes.NewBuffer(concatSlices(messageFormatVersion, algorithmID, messageID, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType))}, nil, assert.Error},
 		{"incompleteBuffer", args{bytes.NewBuffer(concatSlices(messageFormatVersion, algorithmID, messageID, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, frameLength))}, nil, assert.Error},
 		{"validBuffer", args{bytes.NewBuffer(concatSlices(messageFormatVersion, algorithmID, messageID, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, frameLength, algorithmSuiteData))}, mh1Mock, assert.NoError},
+
+		{"unsupportedVersion", args{bytes.NewBuffer(bytes.Repeat([]byte{0x03}, 77))}, nil, assert.Error},
+		{"smallBufferV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1))}, nil, assert.Error},
+		{"invalidMessageTypeV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, []byte{0x81}, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, reservedV1, ivLenV1, frameLength))}, nil, assert.Error},
+		{"versionMismatchV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmID, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, reservedV1, ivLenV1, frameLength))}, nil, assert.Error},
+		{"incompleteBufferV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType))}, nil, assert.Error},
+		{"invalidReservedV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, []byte{0x0, 0x0, 0x0, 0x1}, ivLenV1, frameLength))}, nil, assert.Error},
+		{"invalidIVLenV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, reservedV1, []byte{0x10}, frameLength))}, nil, assert.Error},
+		{"incompleteBufferV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, reservedV1, ivLenV1))}, nil, assert.Error},
+		{"invalidNonFramedFrameLengthV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), []byte{0x1}, reservedV1, ivLenV1, frameLength))}, nil, assert.Error},
+		{"invalidContentTypeV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), []byte{0x3}, reservedV1, ivLenV1, frameLength))}, nil, assert.Error},
+		{"validBufferV1", args{bytes.NewBuffer(concatSlices(messageFormatVersionV1, messageTypeV1, algorithmIDV1, messageIDV1, aadLen, aadDataBytes, edkCount, edk1Mock.bytes(), edk2Mock.bytes(), contentType, reservedV1, ivLenV1, frameLength))}, mh2Mock, assert.NoError},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -188,3 +246,60 @@ func Test_emh_fromBuffer(t *testing.T) {
 		})
 	}
 }
+
+// header of a 0x0078 message encrypted by the AWS Encryption SDK for Go
+func Test_emh_fromBuffer_KnownAnswerV1(t *testing.T) {
+	headerBytes := []byte{
+		0x01, 0x80, 0x00, 0x78, 0xdc, 0x5d, 0xbf, 0xe3, 0xdb, 0xf8, 0xa5, 0x9f, 0x11, 0x21, 0x1a, 0xbe,
+		0xbc, 0x96, 0x7e, 0xcc, 0x00, 0x19, 0x00, 0x01, 0x00, 0x07, 0x70, 0x75, 0x72, 0x70, 0x6f, 0x73,
+		0x65, 0x00, 0x0c, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x2d, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x00,
+		0x01, 0x00, 0x03, 0x72, 0x61, 0x77, 0x00, 0x07, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x31, 0x00,
+		0x3c, 0x8b, 0xd9, 0x02, 0xd6, 0x18, 0xd0, 0xfe, 0x69, 0x2d, 0xba, 0xfa, 0xf9, 0x38, 0x33, 0x4f,
+		0xc2, 0xfe, 0xaa, 0x18, 0x58, 0xbc, 0x31, 0xb7, 0x6d, 0xb8, 0x4a, 0x8a, 0x2a, 0x93, 0x6f, 0xf7,
+		0x6b, 0x97, 0x49, 0xaf, 0x58, 0x17, 0x6c, 0x7f, 0xb0, 0x78, 0x15, 0x5f, 0xb4, 0xde, 0x32, 0x2b,
+		0xd0, 0xfb, 0xf9, 0x05, 0xbb, 0xb3, 0x84, 0xd0, 0xb1, 0x2b, 0xcf, 0x39, 0x38, 0x02, 0x00, 0x00,
+		0x00, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x80,
+	}
+
+	buf := bytes.NewBuffer(headerBytes)
+	got, err := emh{}.fromBuffer(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, suite.AES_256_GCM_IV12_TAG16_NO_KDF, got.AlgorithmSuite)
+	assert.Equal(t, []byte{
+		0xdc, 0x5d, 0xbf, 0xe3, 0xdb, 0xf8, 0xa5, 0x9f, 0x11, 0x21, 0x1a, 0xbe, 0xbc, 0x96, 0x7e, 0xcc,
+	}, got.MessageID)
+	assert.Equal(t, suite.EncryptionContext{"purpose": "known-answer"}, got.AADData.AsEncryptionContext())
+	assert.Equal(t, 1, got.EncryptedDataKeyCount)
+	assert.Equal(t, providerIdentity("raw"), got.EncryptedDataKeys[0].ProviderID)
+	assert.Equal(t, "static1", got.EncryptedDataKeys[0].ProviderInfo)
+	assert.Len(t, got.EncryptedDataKeys[0].encryptedDataKey, 60)
+	assert.Equal(t, suite.FramedContent, got.contentType)
+	assert.Equal(t, 128, got.FrameLength)
+	assert.Nil(t, got.AlgorithmSuiteData)
+	assert.Equal(t, headerBytes, got.Bytes())
+}
+
+func TestMessageHeader_AuthenticationData(t *testing.T) {
+	edk1Mock, _ := EDK.new(awsKmsProviderID, "test", []byte("test"))
+	mh := MessageHeader{
+		AlgorithmSuite:        suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY,
+		MessageID:             []byte("MessageID12MessageID12MessageID1"),
+		aadLen:                17,
+		AADData:               AAD.NewAADWithEncryptionContext(map[string]string{"test": "testing"}),
+		EncryptedDataKeyCount: 1,
+		EncryptedDataKeys:     []encryptedDataKey{*edk1Mock},
+		contentType:           suite.FramedContent,
+		FrameLength:           1024,
+		AlgorithmSuiteData:    []byte("Algorithm12Algorithm12Algorithm1"),
+	}
+
+	assert.Equal(t, mh.Bytes(), mh.AuthenticationData(nil))
+	assert.Equal(t, mh.Bytes(), mh.AuthenticationData(suite.EncryptionContext{}))
+
+	authOnly := suite.EncryptionContext{"tenant": "a", "region": "b"}
+	want := append(mh.Bytes(), AAD.NewAADWithEncryptionContext(authOnly).Bytes()...)
+	assert.Equal(t, want, mh.AuthenticationData(authOnly))
+	// stored header is not changed
+	assert.Equal(t, 17, mh.AADData.Len())
+}
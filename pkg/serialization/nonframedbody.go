@@ -0,0 +1,117 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/conv"
+)
+
+const (
+	contentLengthFieldBytes = int(8) // contentLengthFieldBytes is length of non-framed contentLength field in bytes, 8 bytes, 64-bit unsigned integer
+
+	// MaxNonFramedContentLength is the maximum length of non-framed content,
+	// 2^36 - 32 bytes, limited by AES-GCM with a single IV.
+	MaxNonFramedContentLength = uint64(1<<36 - 32)
+)
+
+type nonFramedBody struct {
+	iV                []byte // 12, IV is concatenation of 64-bits zero's and sequence number 1 as a 32-bits unsigned int.
+	contentLength     int    // 8, contentLength is the length of encryptedContent as a 64-bit unsigned integer.
+	encryptedContent  []byte // vary, encryptedContent
+	authenticationTag []byte // 16, authenticationTag for the body
+}
+
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (mb messageBody) NewNonFramedBody(algorithmSuite *suite.AlgorithmSuite, IV, ciphertext, authTag []byte) (*nonFramedBody, error) { //nolint:revive,gocritic
+	if algorithmSuite == nil {
+		return nil, fmt.Errorf("empty algorithm suite: %w", errBodySerialize)
+	}
+	if algorithmSuite.EncryptionSuite.IVLen != len(IV) {
+		return nil, fmt.Errorf("IV length mismatch: %w", errBodySerialize)
+	}
+	if algorithmSuite.EncryptionSuite.AuthLen != len(authTag) {
+		return nil, fmt.Errorf("authTag length mismatch: %w", errBodySerialize)
+	}
+	if uint64(len(ciphertext)) > MaxNonFramedContentLength {
+		return nil, fmt.Errorf("content length exceeds non-framed maximum: %w", errBodySerialize)
+	}
+	return &nonFramedBody{
+		iV:                IV,
+		contentLength:     len(ciphertext),
+		encryptedContent:  ciphertext,
+		authenticationTag: authTag,
+	}, nil
+}
+
+func (mb messageBody) nonFramedFromBuffer(algorithmSuite *suite.AlgorithmSuite, buf *bytes.Buffer) (*nonFramedBody, error) {
+	if algorithmSuite == nil {
+		return nil, fmt.Errorf("empty algorithm suite: %w", errBodyDeserialize)
+	}
+	if buf == nil {
+		return nil, fmt.Errorf("empty buffer: %w", errBodyDeserialize)
+	}
+	if buf.Len() < algorithmSuite.EncryptionSuite.IVLen+contentLengthFieldBytes {
+		return nil, fmt.Errorf("empty buffer, cant read IV and contentLength: %w", errBodyDeserialize)
+	}
+	IV := buf.Next(algorithmSuite.EncryptionSuite.IVLen)
+	contentLength := conv.FromBytes.UUint64BigEndian(buf.Next(contentLengthFieldBytes))
+	if contentLength > MaxNonFramedContentLength {
+		return nil, fmt.Errorf("content length exceeds non-framed maximum: %w", errBodyDeserialize)
+	}
+	// on 32-bit platforms int is narrower than non-framed maximum
+	if contentLength > math.MaxInt {
+		return nil, fmt.Errorf("content length exceeds platform maximum: %w", errBodyDeserialize)
+	}
+	if uint64(buf.Len()) < contentLength+uint64(algorithmSuite.EncryptionSuite.AuthLen) {
+		return nil, fmt.Errorf("empty buffer, cant read encryptedContent and authenticationTag: %w", errBodyDeserialize)
+	}
+	encryptedContent := buf.Next(int(contentLength))
+	authenticationTag := buf.Next(algorithmSuite.EncryptionSuite.AuthLen)
+	return &nonFramedBody{
+		iV:                IV,
+		contentLength:     int(contentLength),
+		encryptedContent:  encryptedContent,
+		authenticationTag: authenticationTag,
+	}, nil
+}
+
+func (b *nonFramedBody) len() int {
+	return len(b.iV) + // IV
+		contentLengthFieldBytes + // contentLength
+		len(b.encryptedContent) + // vary
+		len(b.authenticationTag) // must be 16
+}
+
+func (b *nonFramedBody) Bytes() []byte {
+	var buf []byte
+	buf = make([]byte, 0, b.len())
+	buf = MessageBody.AppendNonFramedHeader(buf, b.iV, b.contentLength)
+	buf = append(buf, b.encryptedContent...)
+	buf = append(buf, b.authenticationTag...)
+	return buf
+}
+
+// AppendNonFramedHeader appends the serialized non-framed body fields preceding
+// the encrypted content to b.
+func (mb messageBody) AppendNonFramedHeader(b []byte, IV []byte, contentLength int) []byte { //nolint:gocritic
+	b = append(b, IV...)
+	return append(b, conv.FromInt.Uint64BigEndian(contentLength)...)
+}
+
+func (b *nonFramedBody) IV() []byte {
+	return b.iV
+}
+
+func (b *nonFramedBody) EncryptedContent() []byte {
+	return b.encryptedContent
+}
+
+func (b *nonFramedBody) AuthenticationTag() []byte {
+	return b.authenticationTag
+}
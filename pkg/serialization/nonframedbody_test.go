@@ -0,0 +1,135 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func Test_messageBody_NewNonFramedBody(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x01}, 12)
+	tag := bytes.Repeat([]byte{0x02}, 16)
+	ciphertext := []byte("ciphertext")
+	tests := []struct {
+		name       string
+		alg        *suite.AlgorithmSuite
+		iv         []byte
+		ciphertext []byte
+		tag        []byte
+		want       *nonFramedBody
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{"nil_algorithm", nil, iv, ciphertext, tag, nil, assert.Error},
+		{"invalid_iv", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, iv[:4], ciphertext, tag, nil, assert.Error},
+		{"invalid_tag", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, iv, ciphertext, tag[:4], nil, assert.Error},
+		{"valid", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, iv, ciphertext, tag, &nonFramedBody{iV: iv, contentLength: 10, encryptedContent: ciphertext, authenticationTag: tag}, assert.NoError},
+		{"valid_empty", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, iv, []byte{}, tag, &nonFramedBody{iV: iv, contentLength: 0, encryptedContent: []byte{}, authenticationTag: tag}, assert.NoError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MessageBody.NewNonFramedBody(tt.alg, tt.iv, tt.ciphertext, tt.tag)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_nonFramedBody_Bytes(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x01}, 12)
+	tag := bytes.Repeat([]byte{0x02}, 16)
+	b, err := MessageBody.NewNonFramedBody(suite.AES_128_GCM_IV12_TAG16_NO_KDF, iv, []byte("abc"), tag)
+	assert.NoError(t, err)
+
+	want := make([]byte, 0, 39)
+	want = append(want, iv...)
+	want = append(want, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x3)
+	want = append(want, []byte("abc")...)
+	want = append(want, tag...)
+
+	got := b.Bytes()
+	assert.Equal(t, want, got)
+	assert.Equal(t, len(want), b.len())
+	assert.Equal(t, iv, b.IV())
+	assert.Equal(t, []byte("abc"), b.EncryptedContent())
+	assert.Equal(t, tag, b.AuthenticationTag())
+}
+
+func TestDeserializeNonFramedBody(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x01}, 12)
+	tag := bytes.Repeat([]byte{0x02}, 16)
+	concatSlices := func(slices ...[]byte) []byte {
+		var result []byte
+		for _, slice := range slices {
+			result = append(result, slice...)
+		}
+		return result
+	}
+	valid := concatSlices(iv, []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x3}, []byte("abc"), tag)
+
+	tests := []struct {
+		name        string
+		alg         *suite.AlgorithmSuite
+		buf         *bytes.Buffer
+		want        *nonFramedBody
+		bufLenAfter int
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{"nil_buffer", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, nil, nil, 0, assert.Error},
+		{"empty_buffer", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, bytes.NewBuffer(nil), nil, 0, assert.Error},
+		{"short_buffer", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, bytes.NewBuffer(iv), nil, 12, assert.Error},
+		{"content_too_large", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, bytes.NewBuffer(concatSlices(iv, bytes.Repeat([]byte{0xff}, 8), tag)), nil, 16, assert.Error},
+		{"incomplete_content", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, bytes.NewBuffer(valid[:len(valid)-1]), nil, 18, assert.Error},
+		{"valid", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, bytes.NewBuffer(valid), &nonFramedBody{iV: iv, contentLength: 3, encryptedContent: []byte("abc"), authenticationTag: tag}, 0, assert.NoError},
+		{"signing_large_message", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, bytes.NewBuffer(concatSlices(valid, bytes.Repeat([]byte{0x00}, 200))), nil, 200, assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeserializeNonFramedBody(tt.buf, tt.alg)
+			if tt.buf != nil {
+				assert.Equal(t, tt.bufLenAfter, tt.buf.Len())
+			}
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.want != nil {
+				assert.Equal(t, valid, got.Bytes())
+			}
+		})
+	}
+}
+
+// body of a 0x0078 non-framed message that the AWS Encryption SDK for Go decrypts
+func Test_messageBody_nonFramedFromBuffer_KnownAnswer(t *testing.T) {
+	bodyBytes := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x90, 0xe5, 0x11, 0x2f, 0x4d, 0x10, 0xa0, 0xdc, 0x55, 0x13, 0x48, 0x8c, 0x9d,
+		0x38, 0xac, 0x87, 0xd2, 0xd7, 0x3e, 0x02, 0x7b, 0x37, 0xb2, 0x33, 0x20, 0x5e, 0x76, 0x4f, 0x5d,
+		0xa7, 0x7f, 0x18, 0xe1, 0x59, 0x50, 0x63, 0x71, 0x2e, 0x0c, 0xcb, 0x06, 0x74, 0xf0, 0x8e, 0xa2,
+		0x43, 0xbe, 0x35, 0xf8, 0x9e, 0xde, 0xc9, 0xc1, 0xe0, 0x0b, 0xea, 0x06, 0x3c, 0x23, 0xe3, 0x83,
+		0x83, 0xa1, 0x87, 0x1e, 0x65, 0xde, 0xb2, 0xcb, 0x60, 0xe8, 0x9b, 0xf1, 0x49, 0xfa, 0x5d, 0xf7,
+		0xcd, 0xf0, 0x53, 0x58, 0xd0, 0x61, 0xd6, 0x55, 0x88, 0x11, 0x71, 0x09, 0x3b, 0x20, 0x34, 0x27,
+		0x04, 0x36, 0xd0, 0x19, 0x7b, 0x40, 0xf6, 0xf2, 0x09, 0x2b, 0x43, 0x99, 0x65, 0x27, 0xf2, 0x96,
+		0xc8, 0xe0, 0xe6, 0x37, 0xd7, 0x70, 0x86, 0x68, 0x40, 0xeb, 0xf4, 0xe9, 0x1b, 0xec, 0xa4, 0x2a,
+		0xb5, 0x76, 0xb0, 0xcf, 0xc7, 0xb9, 0xc7, 0xb4, 0x0c, 0xea, 0xd8, 0x5a, 0x2e, 0x5b, 0xf9, 0xba,
+		0x5f, 0x96, 0xec, 0x50, 0x18, 0xc7, 0xdc, 0x30, 0xef, 0xd7, 0x9e, 0x0a, 0xd5, 0x65, 0x8e, 0x4f,
+		0xb3, 0xd1, 0xd3, 0x2f,
+	}
+
+	buf := bytes.NewBuffer(bodyBytes)
+	got, err := MessageBody.nonFramedFromBuffer(suite.AES_256_GCM_IV12_TAG16_NO_KDF, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, bodyBytes[:12], got.IV())
+	assert.Len(t, got.EncryptedContent(), 144)
+	assert.Equal(t, bodyBytes[164:], got.AuthenticationTag())
+	assert.Equal(t, bodyBytes, got.Bytes())
+}
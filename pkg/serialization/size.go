@@ -0,0 +1,173 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	b64 "encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+// publicKeyContextKey is the encryption context key under which materials
+// managers store the signature public key for signing algorithm suites.
+const publicKeyContextKey = "aws-crypto-public-key"
+
+var errMessageSize = errors.New("message size error")
+
+// MessageLayout describes an encrypted message for size calculations.
+type MessageLayout struct {
+	Algorithm   *suite.AlgorithmSuite
+	FrameLength int               // FrameLength is ignored for non-framed content.
+	ContentType suite.ContentType // ContentType is framed or non-framed content.
+	// EncryptionContext is the encryption context passed to Encrypt. The signature
+	// public key is accounted for signing algorithm suites, it must not be included.
+	EncryptionContext suite.EncryptionContext
+	// EncryptedDataKeys are the encrypted data keys the materials manager produces.
+	EncryptedDataKeys []EncryptedDataKeyInfo
+}
+
+// CiphertextLength returns the exact length of an encrypted message with
+// plaintextLength bytes of plaintext and the given layout.
+func CiphertextLength(plaintextLength int64, layout MessageLayout) (int64, error) {
+	if plaintextLength < 0 {
+		return 0, fmt.Errorf("negative plaintext length: %w", errMessageSize)
+	}
+	fixed, err := layout.fixedLen()
+	if err != nil {
+		return 0, err
+	}
+
+	if layout.ContentType == suite.NonFramedContent {
+		if uint64(plaintextLength) > MaxNonFramedContentLength {
+			return 0, fmt.Errorf("plaintext too large for non-framed content: %w", errMessageSize)
+		}
+		return fixed + layout.nonFramedOverhead() + plaintextLength, nil
+	}
+
+	frameLength := int64(layout.FrameLength)
+	fullFrames := plaintextLength / frameLength
+	if fullFrames+1 > suite.MaxFrameSequenceNumber {
+		return 0, fmt.Errorf("frame sequence number exceeds maximum: %w", errMessageSize)
+	}
+	regular, final := layout.frameOverheads()
+	return fixed + fullFrames*(frameLength+regular) + final + plaintextLength%frameLength, nil
+}
+
+// MaxPlaintextLength returns the maximum plaintext length which encrypts into
+// at most ciphertextLength bytes with the given layout.
+//
+// It returns an error if even empty plaintext does not fit.
+func MaxPlaintextLength(ciphertextLength int64, layout MessageLayout) (int64, error) {
+	fixed, err := layout.fixedLen()
+	if err != nil {
+		return 0, err
+	}
+
+	if layout.ContentType == suite.NonFramedContent {
+		available := ciphertextLength - fixed - layout.nonFramedOverhead()
+		if available < 0 {
+			return 0, fmt.Errorf("%d bytes too small for a message: %w", ciphertextLength, errMessageSize)
+		}
+		if uint64(available) > MaxNonFramedContentLength {
+			return int64(MaxNonFramedContentLength), nil
+		}
+		return available, nil
+	}
+
+	frameLength := int64(layout.FrameLength)
+	regular, final := layout.frameOverheads()
+	available := ciphertextLength - fixed - final
+	if available < 0 {
+		return 0, fmt.Errorf("%d bytes too small for a message: %w", ciphertextLength, errMessageSize)
+	}
+	fullFrames := available / (frameLength + regular)
+	if fullFrames+1 > suite.MaxFrameSequenceNumber {
+		fullFrames = suite.MaxFrameSequenceNumber - 1
+	}
+	// final frame content is always shorter than frame length
+	finalContent := available - fullFrames*(frameLength+regular)
+	if finalContent > frameLength-1 {
+		finalContent = frameLength - 1
+	}
+	return fullFrames*frameLength + finalContent, nil
+}
+
+// fixedLen returns the length of the header, header authentication and footer.
+func (l MessageLayout) fixedLen() (int64, error) {
+	if l.Algorithm == nil {
+		return 0, fmt.Errorf("empty algorithm suite: %w", errMessageSize)
+	}
+	frameLength := l.FrameLength
+	if l.ContentType == suite.NonFramedContent {
+		frameLength = 0
+	}
+
+	ec := make(map[string]string, len(l.EncryptionContext)+1)
+	for k, v := range l.EncryptionContext {
+		ec[k] = v
+	}
+	if l.Algorithm.IsSigning() {
+		// compressed public key point, base64 encoded
+		pubKeyLen := 1 + (l.Algorithm.Authentication.Algorithm.Params().BitSize+7)/8
+		ec[publicKeyContextKey] = string(make([]byte, b64.StdEncoding.EncodedLen(pubKeyLen)))
+	}
+
+	edks := make([]encryptedDataKey, 0, len(l.EncryptedDataKeys))
+	for _, k := range l.EncryptedDataKeys {
+		key, err := EDK.new(providerIdentity(k.ProviderID), k.KeyID, make([]byte, k.Length))
+		if err != nil {
+			return 0, fmt.Errorf("EDK: %w", errors.Join(errMessageSize, err))
+		}
+		edks = append(edks, *key)
+	}
+
+	header, err := EncryptedMessageHeader.New(MessageHeaderParams{
+		AlgorithmSuite:     l.Algorithm,
+		MessageID:          make([]byte, l.Algorithm.MessageIDLen()),
+		AADData:            AAD.NewAADWithEncryptionContext(ec),
+		EncryptedDataKeys:  edks,
+		ContentType:        l.ContentType,
+		FrameLength:        frameLength,
+		AlgorithmSuiteData: make([]byte, l.Algorithm.AlgorithmSuiteDataLen()),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("header: %w", errors.Join(errMessageSize, err))
+	}
+
+	authLen := headerAuth{authenticationData: make([]byte, headerAuthDataLen)}.Len()
+	if l.Algorithm.MessageFormatVersion == suite.MessageFormatVersion1 {
+		authLen += l.Algorithm.EncryptionSuite.IVLen
+	}
+
+	footerLen := 0
+	if l.Algorithm.IsSigning() {
+		footerLen = (&footer{signLen: l.Algorithm.Authentication.SignatureLen}).len()
+	}
+
+	return int64(header.Len() + authLen + footerLen), nil
+}
+
+// frameOverheads returns the length of a regular and a final frame without content.
+func (l MessageLayout) frameOverheads() (int64, int64) {
+	return FrameOverheads(l.Algorithm)
+}
+
+// FrameOverheads returns the length of a regular and a final frame without content
+// for the algorithm suite.
+func FrameOverheads(alg *suite.AlgorithmSuite) (int64, int64) {
+	authTag := make([]byte, alg.EncryptionSuite.AuthLen)
+	regular := frame{authenticationTag: authTag}.len()
+	final := frame{isFinal: true, authenticationTag: authTag}.len()
+	return int64(regular), int64(final)
+}
+
+// nonFramedOverhead returns the length of a non-framed body without content.
+func (l MessageLayout) nonFramedOverhead() int64 {
+	return int64((&nonFramedBody{
+		iV:                make([]byte, l.Algorithm.EncryptionSuite.IVLen),
+		authenticationTag: make([]byte, l.Algorithm.EncryptionSuite.AuthLen),
+	}).len())
+}
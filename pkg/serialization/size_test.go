@@ -0,0 +1,66 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func Test_MaxPlaintextLength(t *testing.T) {
+	layout := MessageLayout{
+		Algorithm:         suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384,
+		FrameLength:       128,
+		ContentType:       suite.FramedContent,
+		EncryptionContext: map[string]string{"purpose": "test"},
+		EncryptedDataKeys: []EncryptedDataKeyInfo{{ProviderID: "raw", KeyID: "static1", Length: 60}},
+	}
+	empty, err := CiphertextLength(0, layout)
+	require.NoError(t, err)
+
+	for budget := empty; budget < empty+500; budget++ {
+		n, err := MaxPlaintextLength(budget, layout)
+		require.NoError(t, err)
+		size, err := CiphertextLength(n, layout)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, size, budget)
+		next, err := CiphertextLength(n+1, layout)
+		require.NoError(t, err)
+		assert.Greater(t, next, budget)
+	}
+
+	_, err = MaxPlaintextLength(empty-1, layout)
+	assert.ErrorIs(t, err, errMessageSize)
+
+	layout.ContentType = suite.NonFramedContent
+	empty, err = CiphertextLength(0, layout)
+	require.NoError(t, err)
+	n, err := MaxPlaintextLength(empty+10, layout)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+	n, err = MaxPlaintextLength(1<<40, layout)
+	require.NoError(t, err)
+	assert.Equal(t, int64(MaxNonFramedContentLength), n)
+}
+
+func Test_CiphertextLength_Errors(t *testing.T) {
+	layout := MessageLayout{Algorithm: suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, FrameLength: 128, ContentType: suite.FramedContent}
+
+	_, err := CiphertextLength(-1, layout)
+	assert.ErrorIs(t, err, errMessageSize)
+
+	_, err = CiphertextLength(128*int64(suite.MaxFrameSequenceNumber), layout)
+	assert.ErrorIs(t, err, errMessageSize)
+
+	layout.ContentType = suite.NonFramedContent
+	_, err = CiphertextLength(int64(MaxNonFramedContentLength)+1, layout)
+	assert.ErrorIs(t, err, errMessageSize)
+
+	_, err = CiphertextLength(0, MessageLayout{})
+	assert.ErrorIs(t, err, errMessageSize)
+}
@@ -0,0 +1,228 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/utils/conv"
+)
+
+var errStreamRead = errors.New("stream read error")
+
+// streamReader collects exactly the bytes of a single message part from r,
+// so that the part can be deserialized with the same buffer based parsers.
+//
+// It never reads beyond the message part, the rest of r is left untouched.
+type streamReader struct {
+	r   io.Reader
+	buf *bytes.Buffer
+}
+
+func newStreamReader(r io.Reader) *streamReader {
+	return &streamReader{r: r, buf: new(bytes.Buffer)}
+}
+
+// next reads n bytes from r into the buffer and returns them.
+// Returned slice is valid until the next call to next.
+func (sr *streamReader) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid length %d: %w", n, errStreamRead)
+	}
+	start := sr.buf.Len()
+	if _, err := io.CopyN(sr.buf, sr.r, int64(n)); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("cant read %d bytes: %w", n, errors.Join(errStreamRead, err))
+	}
+	return sr.buf.Bytes()[start:], nil
+}
+
+// ReadHeader reads the message header and header authentication from r
+// field by field and deserializes them. It reads exactly the header and
+// header authentication bytes from r.
+//
+// It returns an error wrapping [io.EOF] if r has no more data at all.
+//
+//goland:noinspection GoExportedFuncWithUnexportedType
+func ReadHeader(r io.Reader, maxEncryptedDataKeys int) (*MessageHeader, *headerAuth, error) { //nolint:revive
+	sr := newStreamReader(r)
+
+	// io.EOF before the first byte means there is no message at all
+	if _, err := io.CopyN(sr.buf, r, int64(singleFieldBytes)); err != nil {
+		return nil, nil, fmt.Errorf("cant read message version: %w", errors.Join(errHeaderDeserialize, err))
+	}
+	version := int(sr.buf.Bytes()[0])
+
+	prefixLen := algorithmIDFieldBytes
+	switch version {
+	case suite.MessageFormatVersion1:
+		prefixLen += singleFieldBytes // message type
+	case suite.MessageFormatVersion2:
+	default:
+		return nil, nil, fmt.Errorf("%v message version not supported: %w", version, errHeaderInvalidVersion)
+	}
+	prefix, err := sr.next(prefixLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("header: %w", errors.Join(errHeaderDeserialize, err))
+	}
+	algorithmSuite, err := suite.Algorithm.FromBytes(prefix[prefixLen-algorithmIDFieldBytes:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if version != algorithmSuite.MessageFormatVersion {
+		return nil, nil, fmt.Errorf("%v message version not equal to Algorithm defined: %w", version, errHeaderInvalidVersion)
+	}
+
+	// messageID and aadLen
+	b, err := sr.next(algorithmSuite.MessageIDLen() + lenFieldBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("header: %w", errors.Join(errHeaderDeserialize, err))
+	}
+	aadLen := fieldReader.readLenFieldBytes(b[len(b)-lenFieldBytes:])
+	if _, err := sr.next(aadLen); err != nil {
+		return nil, nil, fmt.Errorf("header AAD: %w", errors.Join(errHeaderDeserialize, err))
+	}
+
+	b, err = sr.next(countFieldBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("header EDK count: %w", errors.Join(errHeaderDeserialize, err))
+	}
+	edkCount := fieldReader.readCountFieldBytes(b)
+	if errEdk := EDK.validateMinMaxEDKs(edkCount, maxEncryptedDataKeys); errEdk != nil {
+		return nil, nil, errEdk
+	}
+	for i := 0; i < edkCount; i++ {
+		// providerID, providerInfo and encryptedDataKey, each prefixed with its length
+		for j := 0; j < EDK.LenFields; j++ {
+			b, err = sr.next(lenFieldBytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("header EDK: %w", errors.Join(errHeaderDeserialize, err))
+			}
+			if _, err := sr.next(fieldReader.readLenFieldBytes(b)); err != nil {
+				return nil, nil, fmt.Errorf("header EDK: %w", errors.Join(errHeaderDeserialize, err))
+			}
+		}
+	}
+
+	// contentType, reserved field and IV length in format version 1, frameLength, algorithmSuiteData
+	tailLen := singleFieldBytes + frameFieldBytes + algorithmSuite.AlgorithmSuiteDataLen()
+	authLen := headerAuthDataLen
+	if version == suite.MessageFormatVersion1 {
+		tailLen += reservedFieldBytes + singleFieldBytes
+		authLen += algorithmSuite.EncryptionSuite.IVLen
+	}
+	if _, err := sr.next(tailLen + authLen); err != nil {
+		return nil, nil, fmt.Errorf("header: %w", errors.Join(errHeaderDeserialize, err))
+	}
+
+	header, authData, err := DeserializeHeader(sr.buf, maxEncryptedDataKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sr.buf.Len() != 0 {
+		return nil, nil, fmt.Errorf("header length mismatch: %w", errHeaderDeserialize)
+	}
+
+	return header, authData, nil
+}
+
+// ReadFrame reads the next frame from r. It reads exactly the frame bytes from r.
+// Frames must be read in sequence order, the first frame must have sequence number 1.
+func (b *body) ReadFrame(r io.Reader) (frame, error) {
+	sr := newStreamReader(r)
+
+	sequenceNumberOrFinal, err := sr.next(frameFieldBytes)
+	if err != nil {
+		return frame{}, fmt.Errorf("frame: %w", errors.Join(errBodyDeserialize, err))
+	}
+	if bytes.Equal(sequenceNumberOrFinal, finalFrameIndicator) {
+		// sequenceNumber, IV, contentLength
+		fields, err := sr.next(frameFieldBytes + b.algorithmSuite.EncryptionSuite.IVLen + frameFieldBytes)
+		if err != nil {
+			return frame{}, fmt.Errorf("final frame: %w", errors.Join(errBodyDeserialize, err))
+		}
+		contentLength := fieldReader.readFrameField(fields[len(fields)-frameFieldBytes:])
+		if contentLength > b.frameLength {
+			return frame{}, fmt.Errorf("final frame content length exceeds frame length: %w", errBodyDeserialize)
+		}
+		if _, err := sr.next(contentLength + b.algorithmSuite.EncryptionSuite.AuthLen); err != nil {
+			return frame{}, fmt.Errorf("final frame: %w", errors.Join(errBodyDeserialize, err))
+		}
+	} else if _, err := sr.next(b.algorithmSuite.EncryptionSuite.IVLen + b.frameLength + b.algorithmSuite.EncryptionSuite.AuthLen); err != nil {
+		return frame{}, fmt.Errorf("frame: %w", errors.Join(errBodyDeserialize, err))
+	}
+
+	f, err := b.readFrame(sr.buf)
+	if err != nil {
+		return frame{}, fmt.Errorf("frame: %w", errors.Join(errBodyDeserialize, err))
+	}
+	if f.sequenceNumber != b.sequenceNumber {
+		return frame{}, fmt.Errorf("malformed message, frame sequence out of order: %w", errBodyDeserialize)
+	}
+	b.sequenceNumber++
+	return f, nil
+}
+
+// ReadFrameAt reads the frame with sequenceNumber at offset off of r. Regular
+// frames of a message have the same length, offset of any frame can be computed.
+func (mb messageBody) ReadFrameAt(r io.ReaderAt, off int64, algorithmSuite *suite.AlgorithmSuite, frameLength, sequenceNumber int) (frame, error) {
+	b, err := mb.NewBody(algorithmSuite, frameLength)
+	if err != nil {
+		return frame{}, err
+	}
+	b.sequenceNumber = sequenceNumber
+	return b.ReadFrame(io.NewSectionReader(r, off, math.MaxInt64-off))
+}
+
+// ReadNonFramedBody reads single block non-framed content from r.
+// It reads exactly the body bytes from r.
+//
+// Encrypted content is held in memory as a whole, it is limited to [MaxNonFramedContentLength].
+//
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (mb messageBody) ReadNonFramedBody(r io.Reader, algorithmSuite *suite.AlgorithmSuite) (*nonFramedBody, error) { //nolint:revive
+	if algorithmSuite == nil {
+		return nil, fmt.Errorf("empty algorithm suite: %w", errBodyDeserialize)
+	}
+	sr := newStreamReader(r)
+	b, err := sr.next(algorithmSuite.EncryptionSuite.IVLen + contentLengthFieldBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cant read IV and contentLength: %w", errors.Join(errBodyDeserialize, err))
+	}
+	contentLength := conv.FromBytes.UUint64BigEndian(b[len(b)-contentLengthFieldBytes:])
+	if contentLength > MaxNonFramedContentLength {
+		return nil, fmt.Errorf("content length exceeds non-framed maximum: %w", errBodyDeserialize)
+	}
+	// on 32-bit platforms int is narrower than non-framed maximum
+	if contentLength > math.MaxInt-uint64(algorithmSuite.EncryptionSuite.AuthLen) {
+		return nil, fmt.Errorf("content length exceeds platform maximum: %w", errBodyDeserialize)
+	}
+	if _, err := sr.next(int(contentLength) + algorithmSuite.EncryptionSuite.AuthLen); err != nil {
+		return nil, fmt.Errorf("cant read encryptedContent and authenticationTag: %w", errors.Join(errBodyDeserialize, err))
+	}
+	return mb.nonFramedFromBuffer(algorithmSuite, sr.buf)
+}
+
+// FromReader reads the message footer from r. It reads exactly the footer bytes from r.
+func (mf messageFooter) FromReader(alg *suite.AlgorithmSuite, r io.Reader) (*footer, error) {
+	sr := newStreamReader(r)
+	b, err := sr.next(lenFieldBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cant read signLen: %w", errors.Join(errFooter, err))
+	}
+	if signLen := fieldReader.readLenFieldBytes(b); signLen != alg.Authentication.SignatureLen {
+		return nil, fmt.Errorf("invalid signature length: %w", errFooter)
+	}
+	if _, err := sr.next(alg.Authentication.SignatureLen); err != nil {
+		return nil, fmt.Errorf("malformed footer: %w", errors.Join(errFooter, err))
+	}
+	return mf.FromBuffer(alg, sr.buf)
+}
@@ -0,0 +1,169 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialization
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chainifynet/aws-encryption-sdk-go/pkg/suite"
+)
+
+func Test_ReadHeader(t *testing.T) {
+	edk1, _ := EDK.new(awsKmsProviderID, "arn:aws:kms:eu-west-1:123456789011:key/test1", []byte("encrypted1"))
+	edk2, _ := EDK.new("raw", "static1", []byte("encrypted2"))
+
+	tests := []struct {
+		name string
+		alg  *suite.AlgorithmSuite
+		aad  *aadData
+	}{
+		{"v2_with_aad", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, AAD.NewAADWithEncryptionContext(map[string]string{"purpose": "test"})},
+		{"v2_without_aad", suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY, nil},
+		{"v1_with_aad", suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, AAD.NewAADWithEncryptionContext(map[string]string{"purpose": "test"})},
+		{"v1_without_aad", suite.AES_128_GCM_IV12_TAG16_NO_KDF, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := EncryptedMessageHeader.New(MessageHeaderParams{
+				AlgorithmSuite:     tt.alg,
+				MessageID:          bytes.Repeat([]byte{0x01}, tt.alg.MessageIDLen()),
+				AADData:            tt.aad,
+				EncryptedDataKeys:  []encryptedDataKey{*edk1, *edk2},
+				ContentType:        suite.FramedContent,
+				FrameLength:        1024,
+				AlgorithmSuiteData: bytes.Repeat([]byte{0x02}, tt.alg.AlgorithmSuiteDataLen()),
+			})
+			require.NoError(t, err)
+			auth, err := MessageHeaderAuth.New(bytes.Repeat([]byte{0x03}, headerAuthDataLen))
+			if tt.alg.MessageFormatVersion == suite.MessageFormatVersion1 {
+				auth, err = MessageHeaderAuth.NewWithIV(bytes.Repeat([]byte{0x04}, tt.alg.EncryptionSuite.IVLen), bytes.Repeat([]byte{0x03}, headerAuthDataLen))
+			}
+			require.NoError(t, err)
+
+			trailer := []byte("body")
+			message := append(append(header.Bytes(), auth.Serialize()...), trailer...)
+			src := bytes.NewReader(message)
+
+			got, gotAuth, err := ReadHeader(iotest.OneByteReader(src), 10)
+			require.NoError(t, err)
+			assert.Equal(t, header.Bytes(), got.Bytes())
+			assert.Equal(t, auth.Serialize(), gotAuth.Serialize())
+
+			rest, err := io.ReadAll(src)
+			require.NoError(t, err)
+			assert.Equal(t, trailer, rest)
+
+			// truncated header
+			_, _, err = ReadHeader(bytes.NewReader(message[:header.Len()]), 10)
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+			// max encrypted data keys
+			_, _, err = ReadHeader(bytes.NewReader(message), 1)
+			assert.ErrorIs(t, err, ErrMaxEncryptedDataKeys)
+		})
+	}
+}
+
+func Test_ReadHeader_Errors(t *testing.T) {
+	_, _, err := ReadHeader(bytes.NewReader(nil), 10)
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, _, err = ReadHeader(bytes.NewReader([]byte{0x03}), 10)
+	assert.ErrorIs(t, err, errHeaderInvalidVersion)
+
+	// format version 2 with format version 1 algorithm ID
+	_, _, err = ReadHeader(bytes.NewReader([]byte{0x02, 0x01, 0x78}), 10)
+	assert.ErrorIs(t, err, errHeaderInvalidVersion)
+}
+
+func Test_body_ReadFrame(t *testing.T) {
+	alg := suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY
+	frameLength := 128
+	iv := make([]byte, alg.EncryptionSuite.IVLen)
+	tag := make([]byte, alg.EncryptionSuite.AuthLen)
+
+	written, err := MessageBody.NewBody(alg, frameLength)
+	require.NoError(t, err)
+	require.NoError(t, written.AddFrame(false, 1, iv, frameLength, bytes.Repeat([]byte{0x01}, frameLength), tag))
+	require.NoError(t, written.AddFrame(false, 2, iv, frameLength, bytes.Repeat([]byte{0x02}, frameLength), tag))
+	require.NoError(t, written.AddFrame(true, 3, iv, 10, bytes.Repeat([]byte{0x03}, 10), tag))
+	src := bytes.NewReader(append(written.Bytes(), []byte("footer")...))
+
+	body, err := MessageBody.NewBody(alg, frameLength)
+	require.NoError(t, err)
+	for _, want := range written.Frames() {
+		got, err := body.ReadFrame(iotest.OneByteReader(src))
+		require.NoError(t, err)
+		assert.Equal(t, want.Bytes(), got.Bytes())
+		assert.Equal(t, want.IsFinal(), got.IsFinal())
+	}
+	rest, err := io.ReadAll(src)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("footer"), rest)
+
+	// frames out of order
+	outOfOrder, err := MessageBody.NewBody(alg, frameLength)
+	require.NoError(t, err)
+	_, err = outOfOrder.ReadFrame(bytes.NewReader(written.Frames()[1].Bytes()))
+	assert.ErrorIs(t, err, errBodyDeserialize)
+
+	// truncated frame
+	truncated, err := MessageBody.NewBody(alg, frameLength)
+	require.NoError(t, err)
+	_, err = truncated.ReadFrame(bytes.NewReader(written.Frames()[0].Bytes()[:50]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// final frame content length larger than frame length
+	large := written.Frames()[2]
+	large.contentLength = frameLength + 1
+	large.encryptedContent = bytes.Repeat([]byte{0x03}, frameLength+1)
+	body, err = MessageBody.NewBody(alg, frameLength)
+	require.NoError(t, err)
+	body.sequenceNumber = 3
+	_, err = body.ReadFrame(bytes.NewReader(large.Bytes()))
+	assert.ErrorIs(t, err, errBodyDeserialize)
+}
+
+func Test_messageBody_ReadNonFramedBody(t *testing.T) {
+	alg := suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY
+	written, err := MessageBody.NewNonFramedBody(alg, make([]byte, alg.EncryptionSuite.IVLen), []byte("ciphertext"), make([]byte, alg.EncryptionSuite.AuthLen))
+	require.NoError(t, err)
+	src := bytes.NewReader(append(written.Bytes(), []byte("footer")...))
+
+	got, err := MessageBody.ReadNonFramedBody(iotest.OneByteReader(src), alg)
+	require.NoError(t, err)
+	assert.Equal(t, written.Bytes(), got.Bytes())
+
+	rest, err := io.ReadAll(src)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("footer"), rest)
+
+	_, err = MessageBody.ReadNonFramedBody(bytes.NewReader(written.Bytes()[:20]), alg)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = MessageBody.ReadNonFramedBody(bytes.NewReader(written.Bytes()), nil)
+	assert.ErrorIs(t, err, errBodyDeserialize)
+}
+
+func Test_messageFooter_FromReader(t *testing.T) {
+	alg := suite.AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
+	written, err := MessageFooter.NewFooter(alg, bytes.Repeat([]byte{0x01}, alg.Authentication.SignatureLen))
+	require.NoError(t, err)
+
+	got, err := MessageFooter.FromReader(alg, iotest.OneByteReader(bytes.NewReader(written.Bytes())))
+	require.NoError(t, err)
+	assert.Equal(t, written.Bytes(), got.Bytes())
+
+	_, err = MessageFooter.FromReader(alg, bytes.NewReader(written.Bytes()[:10]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = MessageFooter.FromReader(alg, bytes.NewReader([]byte{0x00, 0x01, 0x01}))
+	assert.ErrorIs(t, err, errFooter)
+}
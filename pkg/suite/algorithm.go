@@ -25,6 +25,7 @@ type cipherMode string
 
 const (
 	messageIDLen          = int(32)
+	messageIDLenV1        = int(16)
 	algorithmSuiteDataLen = int(32)
 
 	bitSize        = int(8) // 1 byte = 8 bits
@@ -62,8 +63,9 @@ type kdfSuite struct {
 
 //goland:noinspection GoSnakeCaseUsage,GoUnusedGlobalVariable
 var (
-	hkdf_SHA256 = NewKdfSuite(hkdf.New, sha256.New)    //nolint:unused
-	hkdf_SHA384 = NewKdfSuite(hkdf.New, sha512.New384) //nolint:unused
+	kdf_NONE    = NewKdfSuite(nil, nil) // identity KDF, data key is used as is
+	hkdf_SHA256 = NewKdfSuite(hkdf.New, sha256.New)
+	hkdf_SHA384 = NewKdfSuite(hkdf.New, sha512.New384)
 	hkdf_SHA512 = NewKdfSuite(hkdf.New, sha512.New)
 )
 
@@ -102,24 +104,39 @@ func (as *AlgorithmSuite) GoString() string {
 	return fmt.Sprintf("%#v", *as)
 }
 
+// Name returns the algorithm suite name as defined in the AWS Encryption SDK specification.
+//
+// Examples:
+//   - AES_128_GCM_IV12_TAG16_NO_KDF
+//   - AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384
+//   - AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
 func (as *AlgorithmSuite) Name() string {
-	if as.IsSigning() {
-		// AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
-		return fmt.Sprintf("%v_%d_%v_HKDF_SHA%d_COMMIT_KEY_ECDSA_P%d",
-			as.EncryptionSuite.Algorithm,
-			as.EncryptionSuite.DataKeyLen*bitSize,
-			as.EncryptionSuite.Mode,
-			as.KDFSuite.HashFunc().Size()*bitSize,
-			as.Authentication.Algorithm.Params().BitSize,
-		)
-	}
-	// AES_256_GCM_HKDF_SHA512_COMMIT_KEY
-	return fmt.Sprintf("%v_%d_%v_HKDF_SHA%d_COMMIT_KEY",
+	// AES_256_GCM
+	name := fmt.Sprintf("%v_%d_%v",
 		as.EncryptionSuite.Algorithm,
 		as.EncryptionSuite.DataKeyLen*bitSize,
 		as.EncryptionSuite.Mode,
-		as.KDFSuite.HashFunc().Size()*bitSize,
 	)
+	switch {
+	case as.IsCommitting():
+		// AES_256_GCM_HKDF_SHA512_COMMIT_KEY
+		name += fmt.Sprintf("_HKDF_SHA%d_COMMIT_KEY", as.KDFSuite.HashFunc().Size()*bitSize)
+	case as.IsKDFSupported():
+		// AES_256_GCM_IV12_TAG16_HKDF_SHA256
+		name += fmt.Sprintf("_IV%d_TAG%d_HKDF_SHA%d",
+			as.EncryptionSuite.IVLen,
+			as.EncryptionSuite.AuthLen,
+			as.KDFSuite.HashFunc().Size()*bitSize,
+		)
+	default:
+		// AES_256_GCM_IV12_TAG16_NO_KDF
+		name += fmt.Sprintf("_IV%d_TAG%d_NO_KDF", as.EncryptionSuite.IVLen, as.EncryptionSuite.AuthLen)
+	}
+	if as.IsSigning() {
+		// AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384
+		name += fmt.Sprintf("_ECDSA_P%d", as.Authentication.Algorithm.Params().BitSize)
+	}
+	return name
 }
 
 func (as *AlgorithmSuite) String() string {
@@ -135,6 +152,12 @@ func (as *AlgorithmSuite) IsSigning() bool {
 	return as.Authentication.Algorithm != nil
 }
 
+// IsKDFSupported reports whether the algorithm suite derives data encryption key
+// using HKDF. Algorithm suites with identity KDF use data key as is.
+func (as *AlgorithmSuite) IsKDFSupported() bool {
+	return as.KDFSuite.KDFFunc != nil
+}
+
 func (as *AlgorithmSuite) IsCommitting() bool {
 	if bytes.HasPrefix(as.IDBytes(), []byte{0x05}) || bytes.HasPrefix(as.IDBytes(), []byte{0x04}) {
 		return true
@@ -143,19 +166,46 @@ func (as *AlgorithmSuite) IsCommitting() bool {
 }
 
 func (as *AlgorithmSuite) MessageIDLen() int {
+	// all algorithmSuite version 1 has 16 bytes MessageID length
+	if as.MessageFormatVersion == MessageFormatVersion1 {
+		return messageIDLenV1
+	}
 	// all supported algorithmSuite version 2 has 32 bytes MessageID length
 	return messageIDLen
 }
 
 func (as *AlgorithmSuite) AlgorithmSuiteDataLen() int {
+	// algorithmSuite version 1 does not have Algorithm Suite Data field
+	if as.MessageFormatVersion == MessageFormatVersion1 {
+		return 0
+	}
 	// all supported algorithmSuite version 2 has 32 bytes Algorithm Suite Data field length
 	return algorithmSuiteDataLen
 }
 
+// Algorithm suites without key commitment, message format version 1.
+// Supported for decryption of messages produced by legacy AWS Encryption SDK versions,
+// and for encryption only with [CommitmentPolicyForbidEncryptAllowDecrypt] policy.
+//
+//goland:noinspection GoSnakeCaseUsage,GoUnusedGlobalVariable
+var (
+	AES_128_GCM_IV12_TAG16_NO_KDF                 = newAlgorithmSuite(0x0014, aes_128_GCM_IV12_TAG16, MessageFormatVersion1, kdf_NONE, authSuite_NONE)
+	AES_192_GCM_IV12_TAG16_NO_KDF                 = newAlgorithmSuite(0x0046, aes_192_GCM_IV12_TAG16, MessageFormatVersion1, kdf_NONE, authSuite_NONE)
+	AES_256_GCM_IV12_TAG16_NO_KDF                 = newAlgorithmSuite(0x0078, aes_256_GCM_IV12_TAG16, MessageFormatVersion1, kdf_NONE, authSuite_NONE)
+	AES_128_GCM_IV12_TAG16_HKDF_SHA256            = newAlgorithmSuite(0x0114, aes_128_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA256, authSuite_NONE)
+	AES_192_GCM_IV12_TAG16_HKDF_SHA256            = newAlgorithmSuite(0x0146, aes_192_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA256, authSuite_NONE)
+	AES_256_GCM_IV12_TAG16_HKDF_SHA256            = newAlgorithmSuite(0x0178, aes_256_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA256, authSuite_NONE)
+	AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256 = newAlgorithmSuite(0x0214, aes_128_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA256, authSuite_SHA256_ECDSA_P256)
+	AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384 = newAlgorithmSuite(0x0346, aes_192_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA384, authSuite_SHA256_ECDSA_P384)
+	AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384 = newAlgorithmSuite(0x0378, aes_256_GCM_IV12_TAG16, MessageFormatVersion1, hkdf_SHA384, authSuite_SHA256_ECDSA_P384)
+)
+
+// Algorithm suites with key commitment, message format version 2.
+//
 //goland:noinspection GoSnakeCaseUsage,GoUnusedGlobalVariable
 var (
-	AES_256_GCM_HKDF_SHA512_COMMIT_KEY            = newAlgorithmSuite(0x0478, aes_256_GCM_IV12_TAG16, 2, hkdf_SHA512, authSuite_NONE)
-	AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384 = newAlgorithmSuite(0x0578, aes_256_GCM_IV12_TAG16, 2, hkdf_SHA512, authSuite_SHA256_ECDSA_P384)
+	AES_256_GCM_HKDF_SHA512_COMMIT_KEY            = newAlgorithmSuite(0x0478, aes_256_GCM_IV12_TAG16, MessageFormatVersion2, hkdf_SHA512, authSuite_NONE)
+	AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384 = newAlgorithmSuite(0x0578, aes_256_GCM_IV12_TAG16, MessageFormatVersion2, hkdf_SHA512, authSuite_SHA256_ECDSA_P384)
 	//AES_256_GCM_HKDF_SHA512_COMMIT_KEY_WRAPPING            = newAlgorithmSuite(0x0470, aes_256_GCM_IV12_TAG16, 2, hkdf_SHA512, authSuite_NONE)
 	//AES_256_GCM_HKDF_SHA512_COMMIT_KEY_WRAPPING_ECDSA_P384 = newAlgorithmSuite(0x0570, aes_256_GCM_IV12_TAG16, 2, hkdf_SHA512, authSuite_SHA256_ECDSA_P384)
 )
@@ -163,7 +213,7 @@ var (
 // Note: we are not accessing this map concurrently on write, so no need to use sync.Map.
 var algorithmLookup = map[uint16]*AlgorithmSuite{} //nolint:gochecknoglobals
 
-func newAlgorithmSuite(algorithmID uint16, encryptionSuite encryptionSuite, messageFormatVersion int, kdfSuite kdfSuite, authentication authenticationSuite) *AlgorithmSuite { //nolint:unparam
+func newAlgorithmSuite(algorithmID uint16, encryptionSuite encryptionSuite, messageFormatVersion int, kdfSuite kdfSuite, authentication authenticationSuite) *AlgorithmSuite {
 	alg := &AlgorithmSuite{AlgorithmID: algorithmID, EncryptionSuite: encryptionSuite, MessageFormatVersion: messageFormatVersion, KDFSuite: kdfSuite, Authentication: authentication}
 	algorithmLookup[algorithmID] = alg
 	return alg
@@ -22,6 +22,15 @@ func Test_algorithm_ByID(t *testing.T) {
 	}{
 		{"unknown_alg", args{0x0301}, nil, true},
 		{"zero_alg", args{0}, nil, true},
+		{"AES_128_GCM_IV12_TAG16_NO_KDF", args{0x0014}, AES_128_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_192_GCM_IV12_TAG16_NO_KDF", args{0x0046}, AES_192_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_256_GCM_IV12_TAG16_NO_KDF", args{0x0078}, AES_256_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_128_GCM_IV12_TAG16_HKDF_SHA256", args{0x0114}, AES_128_GCM_IV12_TAG16_HKDF_SHA256, false},
+		{"AES_192_GCM_IV12_TAG16_HKDF_SHA256", args{0x0146}, AES_192_GCM_IV12_TAG16_HKDF_SHA256, false},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA256", args{0x0178}, AES_256_GCM_IV12_TAG16_HKDF_SHA256, false},
+		{"AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256", args{0x0214}, AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256, false},
+		{"AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384", args{0x0346}, AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, false},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384", args{0x0378}, AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, false},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY", args{0x0478}, AES_256_GCM_HKDF_SHA512_COMMIT_KEY, false},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384", args{0x0578}, AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, false},
 	}
@@ -52,6 +61,9 @@ func Test_algorithm_FromBytes(t *testing.T) {
 	}{
 		{"alg_nil", args{[]byte(nil)}, nil, true},
 		{"zero_alg", args{[]byte{0x00}}, nil, true},
+		{"AES_128_GCM_IV12_TAG16_NO_KDF", args{[]byte{0x00, 0x14}}, AES_128_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA256", args{[]byte{0x01, 0x78}}, AES_256_GCM_IV12_TAG16_HKDF_SHA256, false},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384", args{[]byte{0x03, 0x78}}, AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, false},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY", args{[]byte{0x04, 0x78}}, AES_256_GCM_HKDF_SHA512_COMMIT_KEY, false},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384", args{[]byte{0x05, 0x78}}, AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, false},
 	}
@@ -121,6 +133,9 @@ func TestAlgorithmSuite_MessageIDLen(t *testing.T) {
 		alg  *AlgorithmSuite
 		want int
 	}{
+		{"NO_KDF", AES_128_GCM_IV12_TAG16_NO_KDF, 16},
+		{"HKDF_SHA256", AES_256_GCM_IV12_TAG16_HKDF_SHA256, 16},
+		{"HKDF_SHA384_ECDSA_P384", AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, 16},
 		{"COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, 32},
 		{"COMMIT_KEY_ECDSA_P384", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, 32},
 	}
@@ -141,6 +156,8 @@ func TestAlgorithmSuite_IsSigning(t *testing.T) {
 	}{
 		{"not_signing", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, false},
 		{"signing", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, true},
+		{"not_signing_NO_KDF", AES_256_GCM_IV12_TAG16_NO_KDF, false},
+		{"signing_ECDSA_P256", AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -160,6 +177,8 @@ func TestAlgorithmSuite_IsCommitting(t *testing.T) {
 		{"COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, true},
 		{"COMMIT_KEY_ECDSA_P384", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, true},
 		{"NO_COMMIT_KEY", newAlgorithmSuite(0x0302, aes_256_GCM_IV12_TAG16, 2, hkdf_SHA512, authSuite_NONE), false},
+		{"NO_KDF", AES_128_GCM_IV12_TAG16_NO_KDF, false},
+		{"HKDF_SHA384_ECDSA_P384", AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -170,12 +189,34 @@ func TestAlgorithmSuite_IsCommitting(t *testing.T) {
 	}
 }
 
+func TestAlgorithmSuite_IsKDFSupported(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  *AlgorithmSuite
+		want bool
+	}{
+		{"AES_128_NO_KDF", AES_128_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_192_NO_KDF", AES_192_GCM_IV12_TAG16_NO_KDF, false},
+		{"AES_256_NO_KDF", AES_256_GCM_IV12_TAG16_NO_KDF, false},
+		{"HKDF_SHA256", AES_128_GCM_IV12_TAG16_HKDF_SHA256, true},
+		{"HKDF_SHA384_ECDSA_P384", AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, true},
+		{"COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.alg.IsKDFSupported())
+		})
+	}
+}
+
 func TestAlgorithmSuite_AlgorithmSuiteDataLen(t *testing.T) {
 	tests := []struct {
 		name string
 		alg  *AlgorithmSuite
 		want int
 	}{
+		{"NO_KDF", AES_256_GCM_IV12_TAG16_NO_KDF, 0},
+		{"HKDF_SHA256", AES_128_GCM_IV12_TAG16_HKDF_SHA256, 0},
 		{"COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, 32},
 		{"COMMIT_KEY_ECDSA_P384", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, 32},
 	}
@@ -212,6 +253,15 @@ func TestAlgorithmSuite_Name(t *testing.T) {
 		alg  *AlgorithmSuite
 		want string
 	}{
+		{"AES_128_GCM_IV12_TAG16_NO_KDF", AES_128_GCM_IV12_TAG16_NO_KDF, "AES_128_GCM_IV12_TAG16_NO_KDF"},
+		{"AES_192_GCM_IV12_TAG16_NO_KDF", AES_192_GCM_IV12_TAG16_NO_KDF, "AES_192_GCM_IV12_TAG16_NO_KDF"},
+		{"AES_256_GCM_IV12_TAG16_NO_KDF", AES_256_GCM_IV12_TAG16_NO_KDF, "AES_256_GCM_IV12_TAG16_NO_KDF"},
+		{"AES_128_GCM_IV12_TAG16_HKDF_SHA256", AES_128_GCM_IV12_TAG16_HKDF_SHA256, "AES_128_GCM_IV12_TAG16_HKDF_SHA256"},
+		{"AES_192_GCM_IV12_TAG16_HKDF_SHA256", AES_192_GCM_IV12_TAG16_HKDF_SHA256, "AES_192_GCM_IV12_TAG16_HKDF_SHA256"},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA256", AES_256_GCM_IV12_TAG16_HKDF_SHA256, "AES_256_GCM_IV12_TAG16_HKDF_SHA256"},
+		{"AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256", AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256, "AES_128_GCM_IV12_TAG16_HKDF_SHA256_ECDSA_P256"},
+		{"AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384", AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, "AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384"},
+		{"AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384", AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, "AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384"},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, "AES_256_GCM_HKDF_SHA512_COMMIT_KEY"},
 		{"AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, "AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384"},
 	}
@@ -228,6 +278,8 @@ func TestAlgorithmSuite_String(t *testing.T) {
 		alg  *AlgorithmSuite
 		want string
 	}{
+		{"NO_KDF", AES_128_GCM_IV12_TAG16_NO_KDF, "AlgID 0x0014: AES_128_GCM_IV12_TAG16_NO_KDF"},
+		{"HKDF_SHA384_ECDSA_P384", AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, "AlgID 0x0378: AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384"},
 		{"COMMIT_KEY", AES_256_GCM_HKDF_SHA512_COMMIT_KEY, "AlgID 0x0478: AES_256_GCM_HKDF_SHA512_COMMIT_KEY"},
 		{"COMMIT_KEY_ECDSA_P384", AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384, "AlgID 0x0578: AES_256_GCM_HKDF_SHA512_COMMIT_KEY_ECDSA_P384"},
 	}
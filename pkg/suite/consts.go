@@ -8,6 +8,13 @@ import "math"
 const (
 	MinFrameSize = int(128)
 	MaxFrameSize = math.MaxUint32
+
+	MaxFrameSequenceNumber = math.MaxUint32 // MaxFrameSequenceNumber is the maximum number of frames in a message
+)
+
+const (
+	MessageFormatVersion1 = int(1) // MessageFormatVersion1 is used by algorithm suites without key commitment
+	MessageFormatVersion2 = int(2) // MessageFormatVersion2 is used by algorithm suites with key commitment
 )
 
 type ContentType uint8
@@ -22,6 +29,7 @@ type ContentAADString string
 const (
 	ContentAADFrame      ContentAADString = "AWSKMSEncryptionClient Frame"
 	ContentAADFinalFrame ContentAADString = "AWSKMSEncryptionClient Final Frame"
+	ContentAADNonFramed  ContentAADString = "AWSKMSEncryptionClient Single Block"
 )
 
 type CommitmentPolicy int8
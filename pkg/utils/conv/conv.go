@@ -83,3 +83,9 @@ func (fb fromBytes) uint32BigEndian(data []byte) uint32 {
 	ui := uint32(data[3]) | uint32(data[2])<<8 | uint32(data[1])<<16 | uint32(data[0])<<24
 	return ui
 }
+
+func (fb fromBytes) UUint64BigEndian(data []byte) uint64 {
+	ui := uint64(data[7]) | uint64(data[6])<<8 | uint64(data[5])<<16 | uint64(data[4])<<24 |
+		uint64(data[3])<<32 | uint64(data[2])<<40 | uint64(data[1])<<48 | uint64(data[0])<<56
+	return ui
+}
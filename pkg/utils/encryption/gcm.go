@@ -37,12 +37,14 @@ type GcmBase interface {
 
 type AEADEncrypter interface {
 	Encrypter
+	EncryptTo(dst, key, iv, plaintext, aadData []byte) ([]byte, error)
 	GenerateHeaderAuth(derivedDataKey, headerBytes []byte) ([]byte, error)
 	ConstructIV(seqNum int) []byte
 }
 
 type AEADDecrypter interface {
 	Decrypter
+	DecryptTo(dst, key, iv, ciphertext, tag, aadData []byte) ([]byte, error)
 	ValidateHeaderAuth(derivedDataKey, headerAuthTag, headerBytes []byte) error
 }
 
@@ -64,10 +66,28 @@ type Gcm struct{}
 //	[]byte: []byte(nil)
 //	error: not nil
 func (ge Gcm) Decrypt(key, iv, ciphertext, tag, aadData []byte) ([]byte, error) {
+	return ge.DecryptTo(nil, key, iv, ciphertext, tag, aadData)
+}
+
+// DecryptTo decrypts ciphertext with AES-GCM AEAD and appends plaintext to dst.
+//
+// If tag immediately follows ciphertext in memory, as it does in a message buffer,
+// ciphertext and tag are opened in place without copying. Neither is modified.
+// As with [cipher.AEAD], dst may alias ciphertext only exactly, as ciphertext[:0],
+// any other overlap between the spare capacity of dst and ciphertext panics.
+func (ge Gcm) DecryptTo(dst, key, iv, ciphertext, tag, aadData []byte) ([]byte, error) {
 	// TODO validations
 
-	// concat raw_ciphertext + auth_tag
-	ciphertextWithTag := append(ciphertext, tag...) //nolint:gocritic
+	var ciphertextWithTag []byte
+	if n := len(ciphertext); len(tag) > 0 && cap(ciphertext)-n >= len(tag) && &ciphertext[:n+len(tag)][n] == &tag[0] {
+		ciphertextWithTag = ciphertext[:n+len(tag)]
+	} else {
+		// concat raw_ciphertext + auth_tag into a new slice, appending to ciphertext
+		// would write into the message buffer it is sliced from
+		ciphertextWithTag = make([]byte, 0, len(ciphertext)+len(tag))
+		ciphertextWithTag = append(ciphertextWithTag, ciphertext...)
+		ciphertextWithTag = append(ciphertextWithTag, tag...)
+	}
 
 	c, err := aes.NewCipher(key)
 	if err != nil {
@@ -79,8 +99,8 @@ func (ge Gcm) Decrypt(key, iv, ciphertext, tag, aadData []byte) ([]byte, error)
 		return nil, fmt.Errorf("AEAD error: %v: %w", err.Error(), ErrGcmDecrypt)
 	}
 
-	// nil, IV/nonce, (raw_ciphertext + auth_tag), aadData
-	plaintext, err := aesGCM.Open(nil, iv, ciphertextWithTag, aadData)
+	// dst, IV/nonce, (raw_ciphertext + auth_tag), aadData
+	plaintext, err := aesGCM.Open(dst, iv, ciphertextWithTag, aadData)
 	if err != nil {
 		return nil, fmt.Errorf("AES error: %v: %w", err.Error(), ErrGcmDecrypt)
 	}
@@ -88,27 +108,38 @@ func (ge Gcm) Decrypt(key, iv, ciphertext, tag, aadData []byte) ([]byte, error)
 }
 
 func (ge Gcm) Encrypt(key, iv, plaintext, aadData []byte) ([]byte, []byte, error) {
+	// ciphertext[:ciphertext len - tagSize], tag[:ciphertext len - tagSize]
+	//	= nil, IV/nonce, plaintext, aadData
+	ciphertext, err := ge.EncryptTo(nil, key, iv, plaintext, aadData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tag := ciphertext[len(ciphertext)-aesGCMTagSize:]
+
+	ciphertext = ciphertext[:len(ciphertext)-aesGCMTagSize]
+
+	return ciphertext, tag, nil
+}
+
+// EncryptTo encrypts plaintext with AES-GCM AEAD and appends ciphertext
+// followed by the authentication tag to dst.
+// As with [cipher.AEAD], dst may alias plaintext only exactly, as plaintext[:0],
+// any other overlap between the spare capacity of dst and plaintext panics.
+func (ge Gcm) EncryptTo(dst, key, iv, plaintext, aadData []byte) ([]byte, error) {
 	// TODO andrew add validations
 
 	c, err := aes.NewCipher(key)
 	if err != nil {
-		return nil, nil, fmt.Errorf("cipher error: %v: %w", err.Error(), ErrGcmEncrypt)
+		return nil, fmt.Errorf("cipher error: %v: %w", err.Error(), ErrGcmEncrypt)
 	}
 
 	aesGCM, err := cipher.NewGCMWithTagSize(c, aesGCMTagSize)
 	if err != nil {
-		return nil, nil, fmt.Errorf("AEAD error: %v: %w", err.Error(), ErrGcmEncrypt)
+		return nil, fmt.Errorf("AEAD error: %v: %w", err.Error(), ErrGcmEncrypt)
 	}
 
-	// ciphertext[:ciphertext len - tagSize], tag[:ciphertext len - tagSize]
-	//	= nil, IV/nonce, plaintext, aadData
-	ciphertext := aesGCM.Seal(nil, iv, plaintext, aadData)
-
-	tag := ciphertext[len(ciphertext)-aesGCMTagSize:]
-
-	ciphertext = ciphertext[:len(ciphertext)-aesGCMTagSize]
-
-	return ciphertext, tag, nil
+	return aesGCM.Seal(dst, iv, plaintext, aadData), nil
 }
 
 // ValidateHeaderAuth validates header authorization
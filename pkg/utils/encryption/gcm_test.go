@@ -170,3 +170,44 @@ func Test_Gcm_ConstructIV(t *testing.T) {
 		})
 	}
 }
+
+func Test_Gcm_EncryptTo_DecryptTo(t *testing.T) {
+	ge := Gcm{}
+	prefix := []byte("prefix")
+
+	sealed, err := ge.EncryptTo(append([]byte(nil), prefix...), key, iv, plainText, aadData)
+	if err != nil {
+		t.Fatalf("EncryptTo() error = %v", err)
+	}
+	if !bytes.HasPrefix(sealed, prefix) || len(sealed) != len(prefix)+len(plainText)+aesGCMTagSize {
+		t.Fatalf("EncryptTo() got length %d, prefix not preserved", len(sealed))
+	}
+	wantCiphertext, wantTag, _ := ge.Encrypt(key, iv, plainText, aadData)
+	if !bytes.Equal(sealed[len(prefix):], append(wantCiphertext, wantTag...)) {
+		t.Fatalf("EncryptTo() differs from Encrypt()")
+	}
+
+	msg := append([]byte(nil), sealed[len(prefix):]...)
+	ciphertext, tag := msg[:len(plainText)], msg[len(plainText):]
+	tests := []struct {
+		name string
+		tag  []byte
+	}{
+		{"contiguous_tag", tag},
+		{"separate_tag", append([]byte(nil), tag...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ge.DecryptTo(append([]byte(nil), prefix...), key, iv, ciphertext, tt.tag, aadData)
+			if err != nil {
+				t.Fatalf("DecryptTo() error = %v", err)
+			}
+			if !bytes.Equal(got, append(append([]byte(nil), prefix...), plainText...)) {
+				t.Errorf("DecryptTo() got = %q", got)
+			}
+			if !bytes.Equal(msg, sealed[len(prefix):]) {
+				t.Errorf("DecryptTo() modified ciphertext")
+			}
+		})
+	}
+}
@@ -17,21 +17,44 @@ var errKeyDerivation = errors.New("key derivation error")
 const (
 	deriveKeyLabel       = "DERIVEKEY" // label to calculate the derived key
 	commitLabel          = "COMMITKEY" // label to calculate the commitment key
+	algorithmIDSize      = 2           // AlgorithmID as big-endian 16-bit unsigned integer
 	deriveKeyKdfInfoSize = 11          // 2 bytes AlgorithmID(uint16) + 9 bytes deriveKeyLabel label
 	commitKdfInfoSize    = 9           // 9 bytes commitLabel
 	lengthCommit         = 32          // used in serialization to calculate the commitment key length
 )
 
+// DeriveDataEncryptionKey derives data encryption key from the dataKey
+// according to the algorithm suite.
+//
+//   - Identity KDF suites use dataKey as is, a copy of dataKey is returned.
+//   - Suites without key commitment use HKDF without salt,
+//     info is AlgorithmID followed by messageID.
+//   - Suites with key commitment use HKDF with messageID as salt,
+//     info is AlgorithmID followed by DERIVEKEY label.
 func DeriveDataEncryptionKey(dataKey []byte, alg *suite.AlgorithmSuite, messageID []byte) ([]byte, error) {
+	if alg != nil && !alg.IsKDFSupported() {
+		return deriveIdentityKey(dataKey, alg)
+	}
 	if err := validateInputs(dataKey, alg); err != nil {
 		return nil, fmt.Errorf("validate error: %v: %w", err.Error(), errKeyDerivation)
 	}
-	var buf []byte
-	buf = make([]byte, 0, deriveKeyKdfInfoSize) // 2 bytes AlgorithmID + 9 bytes label
-	buf = append(buf, conv.FromInt.UUint16BigEndian(alg.AlgorithmID)...)
-	buf = append(buf, []byte(deriveKeyLabel)...)
 
-	kdf := alg.KDFSuite.KDFFunc(alg.KDFSuite.HashFunc, dataKey, messageID, buf)
+	var kdf io.Reader
+	if alg.IsCommitting() {
+		var buf []byte
+		buf = make([]byte, 0, deriveKeyKdfInfoSize) // 2 bytes AlgorithmID + 9 bytes label
+		buf = append(buf, conv.FromInt.UUint16BigEndian(alg.AlgorithmID)...)
+		buf = append(buf, []byte(deriveKeyLabel)...)
+
+		kdf = alg.KDFSuite.KDFFunc(alg.KDFSuite.HashFunc, dataKey, messageID, buf)
+	} else {
+		var buf []byte
+		buf = make([]byte, 0, algorithmIDSize+len(messageID)) // 2 bytes AlgorithmID + messageID
+		buf = append(buf, conv.FromInt.UUint16BigEndian(alg.AlgorithmID)...)
+		buf = append(buf, messageID...)
+
+		kdf = alg.KDFSuite.KDFFunc(alg.KDFSuite.HashFunc, dataKey, nil, buf)
+	}
 
 	derivedKey := make([]byte, alg.EncryptionSuite.DataKeyLen)
 	if _, err := io.ReadFull(kdf, derivedKey); err != nil {
@@ -44,6 +67,9 @@ func CalculateCommitmentKey(dataKey []byte, alg *suite.AlgorithmSuite, messageID
 	if err := validateInputs(dataKey, alg); err != nil {
 		return nil, fmt.Errorf("validate error: %v: %w", err.Error(), errKeyDerivation)
 	}
+	if !alg.IsCommitting() {
+		return nil, fmt.Errorf("algorithm suite %v does not support key commitment: %w", alg, errKeyDerivation)
+	}
 	var buf []byte
 	buf = make([]byte, 0, commitKdfInfoSize) // 9 bytes commitLabel
 	buf = append(buf, []byte(commitLabel)...)
@@ -57,6 +83,15 @@ func CalculateCommitmentKey(dataKey []byte, alg *suite.AlgorithmSuite, messageID
 	return commitmentKey, nil
 }
 
+func deriveIdentityKey(dataKey []byte, alg *suite.AlgorithmSuite) ([]byte, error) {
+	if len(dataKey) != alg.EncryptionSuite.DataKeyLen {
+		return nil, fmt.Errorf("identity KDF: data key length is invalid: %w", errKeyDerivation)
+	}
+	derivedKey := make([]byte, len(dataKey))
+	copy(derivedKey, dataKey)
+	return derivedKey, nil
+}
+
 func validateInputs(dataKey []byte, alg *suite.AlgorithmSuite) error {
 	if len(dataKey) == 0 {
 		return fmt.Errorf("data key is empty")
@@ -4,6 +4,7 @@
 package keyderivation
 
 import (
+	"bytes"
 	"crypto/sha512"
 	"testing"
 
@@ -67,3 +68,55 @@ func Test_validateInputs(t *testing.T) {
 		})
 	}
 }
+
+func Test_DeriveDataEncryptionKey_NonCommitting(t *testing.T) {
+	messageID := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	tests := []struct {
+		name       string
+		dk         []byte
+		alg        *suite.AlgorithmSuite
+		want       []byte
+		wantErr    bool
+		wantErrStr string
+	}{
+		{"identity_aes128", bytes.Repeat([]byte{0x01}, 16), suite.AES_128_GCM_IV12_TAG16_NO_KDF, bytes.Repeat([]byte{0x01}, 16), false, ""},
+		{"identity_aes256", bytes.Repeat([]byte{0x02}, 32), suite.AES_256_GCM_IV12_TAG16_NO_KDF, bytes.Repeat([]byte{0x02}, 32), false, ""},
+		{"identity_invalid_len", bytes.Repeat([]byte{0x02}, 16), suite.AES_256_GCM_IV12_TAG16_NO_KDF, nil, true, "identity KDF: data key length is invalid"},
+		{"identity_empty", nil, suite.AES_192_GCM_IV12_TAG16_NO_KDF, nil, true, "identity KDF: data key length is invalid"},
+		{"hkdf_sha256_aes128", bytes.Repeat([]byte{0x01}, 16), suite.AES_128_GCM_IV12_TAG16_HKDF_SHA256, []byte{0x7c, 0x2e, 0x2d, 0x40, 0xf1, 0x02, 0xd1, 0x40, 0xd2, 0xc2, 0x1f, 0xb6, 0xe3, 0xc3, 0xc7, 0xbb}, false, ""},
+		{"hkdf_sha256_aes256", bytes.Repeat([]byte{0x02}, 32), suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, []byte{0x6b, 0x9d, 0x0a, 0x52, 0xe0, 0xfc, 0xa8, 0x26, 0xf4, 0x05, 0xe6, 0xbc, 0xb3, 0x86, 0xb3, 0x26, 0x6a, 0x14, 0xc6, 0xdf, 0x04, 0x91, 0x40, 0xa2, 0x28, 0x6a, 0x7f, 0x2c, 0x3d, 0x85, 0xd1, 0xbe}, false, ""},
+		{"hkdf_sha384_aes192_p384", bytes.Repeat([]byte{0x03}, 24), suite.AES_192_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, []byte{0xa2, 0xb8, 0xb2, 0x1b, 0x2c, 0x2a, 0x9d, 0xa2, 0x61, 0x3c, 0x1c, 0x15, 0x0c, 0x0a, 0x66, 0x27, 0xe1, 0x22, 0x01, 0xc9, 0xdd, 0x95, 0x17, 0x3e}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeriveDataEncryptionKey(tt.dk, tt.alg, messageID)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errKeyDerivation)
+				assert.ErrorContains(t, err, tt.wantErrStr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, got, tt.alg.EncryptionSuite.DataKeyLen)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// data key and message ID of a 0x0378 message encrypted by the AWS Encryption SDK for Go,
+// the derived key decrypts that message.
+func Test_DeriveDataEncryptionKey_KnownAnswer(t *testing.T) {
+	dataKey := []byte{0xb2, 0x7f, 0x8a, 0xc6, 0x81, 0xa8, 0x73, 0x9d, 0xa5, 0x31, 0x86, 0x9f, 0x26, 0x68, 0xb2, 0xd6, 0xd3, 0xb7, 0xde, 0xf1, 0x77, 0xfc, 0x6c, 0xb6, 0xe2, 0x2e, 0x38, 0xc0, 0xbb, 0xee, 0x2d, 0xec}
+	messageID := []byte{0xf0, 0xb0, 0xa1, 0x7c, 0xbf, 0x50, 0x79, 0x50, 0x10, 0x28, 0x44, 0x28, 0xc5, 0xe8, 0x3e, 0xfd}
+	want := []byte{0x2a, 0x7d, 0xa2, 0xf1, 0x56, 0x33, 0xe2, 0x0d, 0xbb, 0x67, 0xce, 0x41, 0x32, 0x40, 0xb1, 0xbe, 0x47, 0xf0, 0xe3, 0x19, 0xac, 0x8b, 0x95, 0xf3, 0x56, 0x60, 0x99, 0xcb, 0xde, 0x18, 0xa3, 0x37}
+
+	got, err := DeriveDataEncryptionKey(dataKey, suite.AES_256_GCM_IV12_TAG16_HKDF_SHA384_ECDSA_P384, messageID)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_CalculateCommitmentKey_NonCommitting(t *testing.T) {
+	_, err := CalculateCommitmentKey(bytes.Repeat([]byte{0x01}, 32), suite.AES_256_GCM_IV12_TAG16_HKDF_SHA256, nil)
+	assert.ErrorIs(t, err, errKeyDerivation)
+	assert.ErrorContains(t, err, "does not support key commitment")
+}
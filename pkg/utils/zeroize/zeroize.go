@@ -0,0 +1,33 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package zeroize wipes key material from memory once it is no longer needed.
+package zeroize
+
+import (
+	"crypto/ecdsa"
+	"runtime"
+)
+
+// Bytes overwrites b with zeros. It is a no-op for nil or empty b.
+func Bytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	// keeps the writes above from being optimized away
+	runtime.KeepAlive(b)
+}
+
+// ECDSAPrivateKey overwrites the private scalar of key with zeros. The key
+// must not be used afterwards. It is a no-op for nil key.
+func ECDSAPrivateKey(key *ecdsa.PrivateKey) {
+	if key == nil || key.D == nil {
+		return
+	}
+	words := key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	runtime.KeepAlive(words)
+	key.D.SetInt64(0)
+}
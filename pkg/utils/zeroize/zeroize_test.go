@@ -0,0 +1,37 @@
+// Copyright Chainify Group LTD. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package zeroize
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytes(t *testing.T) {
+	b := []byte("secret key material")
+	Bytes(b)
+	assert.Equal(t, make([]byte, len(b)), b)
+
+	assert.NotPanics(t, func() { Bytes(nil) })
+}
+
+func TestECDSAPrivateKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	words := key.D.Bits()
+
+	ECDSAPrivateKey(key)
+	assert.Zero(t, key.D.Sign())
+	for _, w := range words {
+		assert.Zero(t, w)
+	}
+
+	assert.NotPanics(t, func() { ECDSAPrivateKey(nil) })
+	assert.NotPanics(t, func() { ECDSAPrivateKey(&ecdsa.PrivateKey{}) })
+}